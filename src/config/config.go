@@ -22,6 +22,14 @@ var (
 	JWTRefreshExp       int
 	JWTResetPasswordExp int
 	JWTVerifyEmailExp   int
+	WebhookInterval     int
+	OutboxInterval      int
+	OutboxRetention     int
+	SMTPHost            string
+	SMTPPort            int
+	SMTPUsername        string
+	SMTPPassword        string
+	MailFrom            string
 )
 
 func init() {
@@ -47,6 +55,18 @@ func init() {
 	JWTResetPasswordExp = viper.GetInt("JWT_RESET_PASSWORD_EXP_MINUTES")
 	JWTVerifyEmailExp = viper.GetInt("JWT_VERIFY_EMAIL_EXP_MINUTES")
 
+	// background worker configuration
+	WebhookInterval = positiveInt("WEBHOOK_DISPATCH_INTERVAL_SECONDS", 5)
+	OutboxInterval = positiveInt("OUTBOX_DISPATCH_INTERVAL_SECONDS", 1)
+	OutboxRetention = positiveInt("OUTBOX_RETENTION_HOURS", 168)
+
+	// mail configuration
+	SMTPHost = viper.GetString("SMTP_HOST")
+	SMTPPort = viper.GetInt("SMTP_PORT")
+	SMTPUsername = viper.GetString("SMTP_USERNAME")
+	SMTPPassword = viper.GetString("SMTP_PASSWORD")
+	MailFrom = viper.GetString("MAIL_FROM")
+
 	fmt.Printf("PORT: %d \n", AppPort)
 }
 
@@ -66,6 +86,14 @@ func setDefaults() {
 	viper.SetDefault("JWT_REFRESH_EXP_DAYS", 7)
 	viper.SetDefault("JWT_RESET_PASSWORD_EXP_MINUTES", 30)
 	viper.SetDefault("JWT_VERIFY_EMAIL_EXP_MINUTES", 60)
+	viper.SetDefault("WEBHOOK_DISPATCH_INTERVAL_SECONDS", 5)
+	viper.SetDefault("OUTBOX_DISPATCH_INTERVAL_SECONDS", 1)
+	viper.SetDefault("OUTBOX_RETENTION_HOURS", 168)
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", 1025)
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("MAIL_FROM", "approval@example.com")
 
 	// Bind environment variables
 	viper.BindEnv("APP_ENV", "APP_ENV")
@@ -81,6 +109,26 @@ func setDefaults() {
 	viper.BindEnv("JWT_REFRESH_EXP_DAYS", "JWT_REFRESH_EXP_DAYS")
 	viper.BindEnv("JWT_RESET_PASSWORD_EXP_MINUTES", "JWT_RESET_PASSWORD_EXP_MINUTES")
 	viper.BindEnv("JWT_VERIFY_EMAIL_EXP_MINUTES", "JWT_VERIFY_EMAIL_EXP_MINUTES")
+	viper.BindEnv("WEBHOOK_DISPATCH_INTERVAL_SECONDS", "WEBHOOK_DISPATCH_INTERVAL_SECONDS")
+	viper.BindEnv("OUTBOX_DISPATCH_INTERVAL_SECONDS", "OUTBOX_DISPATCH_INTERVAL_SECONDS")
+	viper.BindEnv("OUTBOX_RETENTION_HOURS", "OUTBOX_RETENTION_HOURS")
+	viper.BindEnv("SMTP_HOST", "SMTP_HOST")
+	viper.BindEnv("SMTP_PORT", "SMTP_PORT")
+	viper.BindEnv("SMTP_USERNAME", "SMTP_USERNAME")
+	viper.BindEnv("SMTP_PASSWORD", "SMTP_PASSWORD")
+	viper.BindEnv("MAIL_FROM", "MAIL_FROM")
+}
+
+// positiveInt reads a setting that must be greater than 0, such as a worker
+// interval (time.NewTicker panics otherwise), and falls back to def when it
+// is not.
+func positiveInt(key string, def int) int {
+	value := viper.GetInt(key)
+	if value <= 0 {
+		log.Warnf("%s must be greater than 0, using %d", key, def)
+		return def
+	}
+	return value
 }
 
 func loadConfig() {
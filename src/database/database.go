@@ -46,7 +46,113 @@ func Connect(dbHost, dbName string) *gorm.DB {
 			&model.Workflow{},
 			&model.Step{},
 			&model.Request{},
+			&model.Approval{},
+			&model.RequestEvent{},
+			&model.UserRole{},
+			&model.Role{},
+			&model.Group{},
+			&model.GroupMember{},
+			&model.IdempotencyKey{},
+			&model.ExchangeRate{},
+			&model.WebhookSubscription{},
+			&model.WebhookDelivery{},
+			&model.OutboxEvent{},
+			&model.NotificationTemplate{},
+			&model.RefreshToken{},
+			&model.DataMigration{},
 		)
+
+		// Requests created before multi-currency support were always in the
+		// workflow currency, so their base amount is the amount itself.
+		db.Exec("UPDATE requests SET base_amount = amount, base_currency = currency, exchange_rate = 1 WHERE base_currency = ''")
+
+		runDataMigration(db, "canonical_step_actors", canonicalizeStepActors)
+		runDataMigration(db, "backfill_user_roles", backfillUserRoles)
+		runDataMigration(db, "backfill_pending_workflow_id", backfillPendingSlots)
 	}
 	return db
 }
+
+// runDataMigration applies a one-off data fix unless it already ran. The fix
+// and its marker are written in one transaction, so a failed run is retried on
+// the next startup.
+func runDataMigration(db *gorm.DB, name string, migrate func(tx *gorm.DB) error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var applied int64
+		if err := tx.Model(&model.DataMigration{}).Where("name = ?", name).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			return nil
+		}
+
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return tx.Create(&model.DataMigration{Name: name}).Error
+	})
+	if err != nil {
+		log.Errorf("Data migration %s failed: %+v", name, err)
+	}
+}
+
+// canonicalizeStepActors rewrites step actors saved before they were stored in
+// canonical form: bare names are roles, kinds are lower-cased, spaces around
+// the colon and leading zeros in user IDs are dropped. Custom roles used by
+// steps or assigned to users before roles were stored get a Role row, so the
+// steps can still be updated.
+func canonicalizeStepActors(tx *gorm.DB) error {
+	statements := []string{
+		"UPDATE steps SET actor = CONCAT('role:', TRIM(actor)) WHERE actor NOT LIKE '%:%'",
+		"UPDATE steps SET actor = CONCAT(LOWER(TRIM(SUBSTRING_INDEX(actor, ':', 1))), ':', TRIM(SUBSTRING(actor, LOCATE(':', actor) + 1))) WHERE actor LIKE '%:%'",
+		"UPDATE steps SET actor = CONCAT('user:', CAST(SUBSTRING(actor, 6) AS UNSIGNED)) WHERE actor LIKE 'user:%'",
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Exec(`INSERT INTO roles (name, created_by, created_at)
+		SELECT MIN(legacy.name), 0, ? FROM (
+			SELECT SUBSTRING(actor, 6) AS name FROM steps WHERE actor LIKE 'role:%'
+			UNION SELECT role FROM user_roles
+		) legacy
+		WHERE LOWER(legacy.name) NOT IN ?
+			AND NOT EXISTS (SELECT 1 FROM roles WHERE LOWER(roles.name) = LOWER(legacy.name))
+		GROUP BY LOWER(legacy.name)`, time.Now(), model.Roles).Error
+}
+
+// backfillUserRoles gives users registered before roles existed the default
+// requester role, and makes the oldest of them admin if nobody is one yet.
+// It runs once: later role removals by an admin are kept.
+func backfillUserRoles(tx *gorm.DB) error {
+	if err := tx.Exec("INSERT INTO user_roles (user_id, role, created_at) SELECT id, ?, ? FROM users WHERE id NOT IN (SELECT user_id FROM user_roles)", model.RoleRequester, time.Now()).Error; err != nil {
+		return err
+	}
+	return tx.Exec("INSERT INTO user_roles (user_id, role, created_at) SELECT MIN(id), ?, ? FROM users WHERE NOT EXISTS (SELECT 1 FROM user_roles WHERE role = ?) HAVING MIN(id) IS NOT NULL", model.RoleAdmin, time.Now(), model.RoleAdmin).Error
+}
+
+// backfillPendingSlots gives the oldest PENDING merge request of each merge
+// workflow the pending slot of its workflow, so the unique index protects
+// requests created before the slot existed. Any other PENDING request of those
+// workflows goes on as a separate request, since only the slot holder is a
+// merge target.
+func backfillPendingSlots(tx *gorm.DB) error {
+	err := tx.Exec(`UPDATE requests
+		JOIN (
+			SELECT MIN(pending.id) AS id FROM requests pending
+			JOIN workflows ON workflows.id = pending.workflow_id
+			WHERE pending.status = 'PENDING' AND pending.submission_mode <> ? AND workflows.submission_mode <> ?
+			GROUP BY pending.workflow_id
+			HAVING SUM(pending.pending_workflow_id IS NOT NULL) = 0
+		) oldest ON oldest.id = requests.id
+		SET requests.pending_workflow_id = requests.workflow_id, requests.submission_mode = ?`,
+		model.SubmissionModeSeparate, model.SubmissionModeSeparate, model.SubmissionModeMerge).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec("UPDATE requests SET submission_mode = ? WHERE status = 'PENDING' AND pending_workflow_id IS NULL",
+		model.SubmissionModeSeparate).Error
+}
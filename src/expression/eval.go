@@ -0,0 +1,218 @@
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type node interface {
+	eval(env map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type identNode struct {
+	path []string
+}
+
+func (n identNode) eval(env map[string]interface{}) (interface{}, error) {
+	var current interface{} = env
+	for _, part := range n.path {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		current = fields[part]
+	}
+	return normalize(current), nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n unaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "!":
+		b, err := toBool(value, n.op)
+		if err != nil {
+			return nil, err
+		}
+		return !b, nil
+	case "-":
+		number, ok := value.(decimal.Decimal)
+		if !ok {
+			return nil, fmt.Errorf("operator - requires a number, got %s", typeName(value))
+		}
+		return number.Neg(), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+type binaryNode struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n binaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short-circuit.
+	switch n.op {
+	case "&&", "||":
+		l, err := toBool(left, n.op)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "&&" && !l {
+			return false, nil
+		}
+		if n.op == "||" && l {
+			return true, nil
+		}
+
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return toBool(right, n.op)
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+
+	if left == nil || right == nil {
+		return false, nil
+	}
+
+	cmp, err := compare(left, right, n.op)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func equal(left, right interface{}) bool {
+	switch l := left.(type) {
+	case decimal.Decimal:
+		r, ok := right.(decimal.Decimal)
+		return ok && l.Equal(r)
+	case string:
+		r, ok := right.(string)
+		return ok && l == r
+	case bool:
+		r, ok := right.(bool)
+		return ok && l == r
+	case nil:
+		return right == nil
+	}
+	return false
+}
+
+func compare(left, right interface{}, op string) (int, error) {
+	switch l := left.(type) {
+	case decimal.Decimal:
+		if r, ok := right.(decimal.Decimal); ok {
+			return l.Cmp(r), nil
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), nil
+		}
+	}
+	return 0, fmt.Errorf("operator %s cannot compare %s with %s", op, typeName(left), typeName(right))
+}
+
+func toBool(value interface{}, op string) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("operator %s requires a boolean, got %s", op, typeName(value))
+}
+
+// normalize converts the values found in the env to the types the evaluator
+// works with, so callers can pass ints or JSON-decoded data directly. Numbers
+// are compared as exact decimals; decode JSON with UseNumber to keep them exact.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return decimal.NewFromInt(int64(v))
+	case int64:
+		return decimal.NewFromInt(v)
+	case uint:
+		return decimal.NewFromUint64(uint64(v))
+	case uint64:
+		return decimal.NewFromUint64(v)
+	case float32:
+		return decimal.NewFromFloat32(v)
+	case float64:
+		return decimal.NewFromFloat(v)
+	case json.Number:
+		if number, err := decimal.NewFromString(v.String()); err == nil {
+			return number
+		}
+	}
+	return value
+}
+
+func parseNumber(text string) (decimal.Decimal, error) {
+	return decimal.NewFromString(text)
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case decimal.Decimal:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
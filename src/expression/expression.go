@@ -0,0 +1,367 @@
+// Package expression implements the small rule language used in step
+// conditions, e.g. `amount > 5000 && metadata.department == "IT"`.
+//
+// Supported syntax:
+//
+//	literals     123, 12.5, "text", 'text', true, false, null
+//	identifiers  amount, metadata.department (resolved against the env map)
+//	comparison   ==, !=, <, <=, >, >=
+//	logic        &&, ||, !
+//	grouping     ( ... )
+//
+// Unknown identifiers evaluate to null. Ordering comparisons against null are
+// false, so a missing metadata field never matches `metadata.x > 10`.
+package expression
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Expression struct {
+	source string
+	root   node
+}
+
+// SyntaxError describes why an expression could not be parsed.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos+1, e.Msg)
+}
+
+// Parse compiles the source into an expression that can be evaluated many times.
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Identifiers returns the distinct root identifiers used by the expression,
+// e.g. "metadata" for metadata.department.
+func (e *Expression) Identifiers() []string {
+	seen := map[string]bool{}
+	var names []string
+	walk(e.root, func(n node) {
+		if id, ok := n.(identNode); ok && !seen[id.path[0]] {
+			seen[id.path[0]] = true
+			names = append(names, id.path[0])
+		}
+	})
+	return names
+}
+
+// Eval evaluates the expression against env.
+func (e *Expression) Eval(env map[string]interface{}) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+// EvalBool evaluates the expression and requires a boolean result.
+func (e *Expression) EvalBool(env map[string]interface{}) (bool, error) {
+	value, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("expression %q does not evaluate to a boolean", e.source)
+}
+
+func walk(n node, fn func(node)) {
+	fn(n)
+	switch v := n.(type) {
+	case unaryNode:
+		walk(v.operand, fn)
+	case binaryNode:
+		walk(v.left, fn)
+		walk(v.right, fn)
+	}
+}
+
+// ========================================================
+// Parser
+// ========================================================
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOperator && p.peek().text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOperator && p.peek().text == "&&" {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if tok.kind == tokenOperator && isComparison(tok.text) {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: tok.text, left: left, right: right}
+
+		if next := p.peek(); next.kind == tokenOperator && isComparison(next.text) {
+			return nil, &SyntaxError{Pos: next.pos, Msg: "comparisons cannot be chained, use && instead"}
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: tok.text, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		value, err := parseNumber(tok.text)
+		if err != nil {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("invalid number %q", tok.text)}
+		}
+		return literalNode{value: value}, nil
+	case tokenString:
+		return literalNode{value: tok.text}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+
+		path := []string{tok.text}
+		for p.peek().kind == tokenDot {
+			p.next()
+			part := p.next()
+			if part.kind != tokenIdent {
+				return nil, &SyntaxError{Pos: part.pos, Msg: "expected field name after '.'"}
+			}
+			path = append(path, part.text)
+		}
+		return identNode{path: path}, nil
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &SyntaxError{Pos: closing.pos, Msg: "expected ')'"}
+		}
+		return inner, nil
+	case tokenEOF:
+		return nil, &SyntaxError{Pos: tok.pos, Msg: "unexpected end of expression"}
+	}
+
+	return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// ========================================================
+// Lexer
+// ========================================================
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+	tokenDot
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	i := 0
+
+	for i < len(source) {
+		ch := source[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case isDigit(ch):
+			start := i
+			for i < len(source) && (isDigit(source[i]) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:i], pos: start})
+		case isIdentStart(ch):
+			start := i
+			for i < len(source) && (isIdentStart(source[i]) || isDigit(source[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:i], pos: start})
+		case ch == '"' || ch == '\'':
+			text, end, err := readString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = end
+		case ch == '.':
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+			i++
+		case ch == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		default:
+			op := readOperator(source[i:])
+			if op == "" {
+				return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", ch)}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, text: "end of expression", pos: len(source)})
+	return tokens, nil
+}
+
+func readOperator(s string) string {
+	for _, op := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "-"} {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func readString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var sb strings.Builder
+
+	for i := start + 1; i < len(source); i++ {
+		ch := source[i]
+		switch {
+		case ch == '\\' && i+1 < len(source):
+			i++
+			switch source[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(source[i])
+			}
+		case ch == quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+
+	return "", 0, &SyntaxError{Pos: start, Msg: "unterminated string"}
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
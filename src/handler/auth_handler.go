@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"technical-test/src/model"
 	"technical-test/src/response"
 	"technical-test/src/usecase"
 	"technical-test/src/utils"
@@ -18,7 +20,7 @@ func NewAuthHandler(authUsecase usecase.AuthUsecase) *AuthHandler {
 
 // Register godoc
 // @Summary Register a new user
-// @Description Create a new user account with name, email, and password
+// @Description Create a new user account with name, email, and password. New accounts get the requester role; the first account also gets the admin role
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -47,12 +49,13 @@ func (h *AuthHandler) Register(c fiber.Ctx) error {
 		"id":    user.ID,
 		"name":  user.Name,
 		"email": user.Email,
+		"roles": user.RoleNames(),
 	}, nil)
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Authenticate user with email and password, returns JWT token
+// @Description Authenticate user with email and password, returns a JWT access token and a refresh token for POST /v1/auth/refresh
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -72,17 +75,58 @@ func (h *AuthHandler) Login(c fiber.Ctx) error {
 		return response.Error(c, utils.FormatValidationError(err), nil)
 	}
 
-	token, user, err := h.authUsecase.Login(body.Email, body.Password)
+	tokens, user, err := h.authUsecase.Login(body.Email, body.Password)
 	if err != nil {
 		return response.Error(c, err.Error(), nil)
 	}
 
-	return response.Success(c, "Login successful", fiber.Map{
-		"token": token,
+	return response.Success(c, "Login successful", tokenResponse(tokens, user), nil)
+}
+
+// Refresh godoc
+// @Summary Refresh the access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token works once; presenting one that was already exchanged revokes every refresh token issued since the same login
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body object{refresh_token=string} true "Refresh Request"
+// @Success 200 {object} response.ResponseSuccess{data=response.LoginResponse} "Token refreshed successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Invalid, expired or reused refresh token"
+// @Router /v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c fiber.Ctx) error {
+	var body struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	tokens, user, err := h.authUsecase.Refresh(body.RefreshToken)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+			c.Status(fiber.StatusUnauthorized)
+			return response.Error(c, err.Error(), nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to refresh token", nil)
+	}
+
+	return response.Success(c, "Token refreshed successfully", tokenResponse(tokens, user), nil)
+}
+
+func tokenResponse(tokens usecase.AuthTokens, user model.User) fiber.Map {
+	return fiber.Map{
+		"token":              tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
 		"user": fiber.Map{
 			"id":    user.ID,
 			"name":  user.Name,
 			"email": user.Email,
+			"roles": user.RoleNames(),
 		},
-	}, nil)
+	}
 }
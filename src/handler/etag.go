@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var (
+	errIfMatchRequired = errors.New("If-Match header is required")
+	errIfMatchInvalid  = errors.New("If-Match does not match the current version")
+)
+
+// setETag exposes the row version so clients can send it back in If-Match.
+func setETag(c fiber.Ctx, version uint) {
+	c.Set(fiber.HeaderETag, `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// ifMatchVersion reads the version a mutating call was based on. "*" accepts
+// any version and is returned as 0, which the usecases treat as "no check".
+func ifMatchVersion(c fiber.Ctx) (uint, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return 0, errIfMatchRequired
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, errIfMatchInvalid
+	}
+	version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 32)
+	if err != nil || version == 0 {
+		return 0, errIfMatchInvalid
+	}
+	return uint(version), nil
+}
+
+// preconditionStatus maps If-Match errors to HTTP status codes.
+func preconditionStatus(err error) int {
+	if errors.Is(err, errIfMatchRequired) {
+		return fiber.StatusPreconditionRequired
+	}
+	return fiber.StatusPreconditionFailed
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"technical-test/src/response"
+	"technical-test/src/usecase"
+	"technical-test/src/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type ExchangeRateHandler struct {
+	exchangeRateUsecase usecase.ExchangeRateUsecase
+}
+
+func NewExchangeRateHandler(exchangeRateUsecase usecase.ExchangeRateUsecase) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		exchangeRateUsecase: exchangeRateUsecase,
+	}
+}
+
+// CreateExchangeRate godoc
+// @Summary Create an exchange rate
+// @Description Add a rate meaning one unit of from_currency is worth rate units of to_currency, valid from effective_date (YYYY-MM-DD) until a newer rate for the same pair
+// @Tags Exchange Rates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param body body object{from_currency=string,to_currency=string,rate=string,effective_date=string} true "Create Exchange Rate Request"
+// @Success 200 {object} response.ResponseSuccess "Exchange rate created successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 409 {object} response.ResponseError "A rate for this pair and date already exists"
+// @Router /v1/exchange-rates [post]
+func (h *ExchangeRateHandler) CreateExchangeRate(c fiber.Ctx) error {
+	var body struct {
+		FromCurrency  string          `json:"from_currency" validate:"required"`
+		ToCurrency    string          `json:"to_currency" validate:"required"`
+		Rate          decimal.Decimal `json:"rate"`
+		EffectiveDate string          `json:"effective_date" validate:"required"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	effectiveDate, err := time.Parse(usecase.EffectiveDateLayout, body.EffectiveDate)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "effective_date must be formatted as YYYY-MM-DD", nil)
+	}
+
+	rate, err := h.exchangeRateUsecase.CreateExchangeRate(body.FromCurrency, body.ToCurrency, body.Rate, effectiveDate)
+	if err != nil {
+		if errors.Is(err, usecase.ErrExchangeRateExists) {
+			c.Status(fiber.StatusConflict)
+		} else {
+			c.Status(fiber.StatusBadRequest)
+		}
+		return response.Error(c, err.Error(), nil)
+	}
+
+	return response.Success(c, "Exchange rate created successfully", rate, nil)
+}
+
+// FindAllExchangeRates godoc
+// @Summary List exchange rates
+// @Description Get exchange rates with pagination, newest effective date first, optionally filtered by currency pair
+// @Tags Exchange Rates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param from query string false "Filter by from currency"
+// @Param to query string false "Filter by to currency"
+// @Success 200 {object} response.ResponseSuccess "Exchange rates retrieved successfully"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 500 {object} response.ResponseError "Internal server error"
+// @Router /v1/exchange-rates [get]
+func (h *ExchangeRateHandler) FindAllExchangeRates(c fiber.Ctx) error {
+	params := utils.GetPaginationParams(c)
+
+	rates, total, err := h.exchangeRateUsecase.FindExchangeRatesWithPagination(params.Page, params.PageSize, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve exchange rates", nil)
+	}
+
+	totalPages := utils.CalculateTotalPages(total, params.PageSize)
+	meta := utils.PaginationMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	data := fiber.Map{
+		"exchange_rates": rates,
+		"pagination":     meta,
+	}
+
+	return response.Success(c, "Exchange rates retrieved successfully", data, nil)
+}
+
+// DeleteExchangeRate godoc
+// @Summary Delete an exchange rate
+// @Description Remove an exchange rate. Requests already converted keep the rate recorded on them
+// @Tags Exchange Rates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param rateId path int true "Exchange Rate ID"
+// @Success 200 {object} response.ResponseSuccess "Exchange rate deleted successfully"
+// @Failure 400 {object} response.ResponseError "Invalid exchange rate ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Exchange rate not found"
+// @Router /v1/exchange-rates/{rateId} [delete]
+func (h *ExchangeRateHandler) DeleteExchangeRate(c fiber.Ctx) error {
+	rateId, err := strconv.Atoi(c.Params("rateId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid exchange rate ID", nil)
+	}
+
+	if err := h.exchangeRateUsecase.DeleteExchangeRate(rateId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Exchange rate not found", nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to delete exchange rate", nil)
+	}
+
+	return response.Success(c, "Exchange rate deleted successfully", nil, nil)
+}
+
+// ImportExchangeRates godoc
+// @Summary Import exchange rates from CSV
+// @Description Import a CSV file with the header from_currency,to_currency,rate,effective_date, either as the multipart field "file" or as a text/csv body. Rows for an existing pair and date replace the stored rate; an invalid row rejects the whole file
+// @Tags Exchange Rates
+// @Security Bearer
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Param file formData file false "CSV file"
+// @Success 200 {object} response.ResponseSuccess "Exchange rates imported successfully"
+// @Failure 400 {object} response.ResponseError "Invalid file"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Router /v1/exchange-rates/import [post]
+func (h *ExchangeRateHandler) ImportExchangeRates(c fiber.Ctx) error {
+	var file io.Reader
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, "file is required", nil)
+		}
+		opened, err := header.Open()
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, "file could not be read", nil)
+		}
+		defer opened.Close()
+		file = opened
+	} else {
+		file = bytes.NewReader(c.Body())
+	}
+
+	imported, err := h.exchangeRateUsecase.ImportExchangeRates(file)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidRateFile) {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, err.Error(), nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to import exchange rates", nil)
+	}
+
+	return response.Success(c, "Exchange rates imported successfully", fiber.Map{"imported": imported}, nil)
+}
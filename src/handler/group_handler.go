@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"technical-test/src/response"
+	"technical-test/src/usecase"
+	"technical-test/src/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type GroupHandler struct {
+	groupUsecase usecase.GroupUsecase
+}
+
+func NewGroupHandler(groupUsecase usecase.GroupUsecase) *GroupHandler {
+	return &GroupHandler{
+		groupUsecase: groupUsecase,
+	}
+}
+
+// CreateGroup godoc
+// @Summary Create a new group
+// @Description Create a group of users that can be used as a step actor (group:<name>)
+// @Tags Groups
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param body body object{name=string} true "Create Group Request"
+// @Success 200 {object} response.ResponseSuccess "Group created successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Router /v1/groups [post]
+func (h *GroupHandler) CreateGroup(c fiber.Ctx) error {
+	var body struct {
+		Name string `json:"name" validate:"required"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	group, err := h.groupUsecase.CreateGroup(body.Name)
+	if err != nil {
+		return response.Error(c, err.Error(), nil)
+	}
+
+	return response.Success(c, "Group created successfully", group, nil)
+}
+
+// FindAllGroups godoc
+// @Summary List all groups
+// @Description Get all groups with pagination support
+// @Tags Groups
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param search query string false "Search by group name"
+// @Success 200 {object} response.ResponseSuccess "Groups retrieved successfully"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 500 {object} response.ResponseError "Internal server error"
+// @Router /v1/groups [get]
+func (h *GroupHandler) FindAllGroups(c fiber.Ctx) error {
+	params := utils.GetPaginationParams(c)
+
+	groups, total, err := h.groupUsecase.FindAllGroupsWithPagination(params.Page, params.PageSize, params.Search)
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve groups", nil)
+	}
+
+	totalPages := utils.CalculateTotalPages(total, params.PageSize)
+	meta := utils.PaginationMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	data := fiber.Map{
+		"groups":     groups,
+		"pagination": meta,
+	}
+
+	return response.Success(c, "Groups retrieved successfully", data, nil)
+}
+
+// GetGroupByID godoc
+// @Summary Get group by ID
+// @Description Retrieve a group and its members
+// @Tags Groups
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param groupId path int true "Group ID"
+// @Success 200 {object} response.ResponseSuccess "Group retrieved successfully"
+// @Failure 400 {object} response.ResponseError "Invalid group ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Group not found"
+// @Router /v1/groups/{groupId} [get]
+func (h *GroupHandler) GetGroupByID(c fiber.Ctx) error {
+	groupId, err := strconv.Atoi(c.Params("groupId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid group ID", nil)
+	}
+
+	group, err := h.groupUsecase.GetGroupByID(groupId)
+	if err != nil {
+		c.Status(fiber.StatusNotFound)
+		return response.Error(c, "Group not found", nil)
+	}
+
+	return response.Success(c, "Group retrieved successfully", group, nil)
+}
+
+// AddGroupMember godoc
+// @Summary Add a member to a group
+// @Description Add an existing user to a group
+// @Tags Groups
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param groupId path int true "Group ID"
+// @Param body body object{user_id=int} true "Add Member Request"
+// @Success 200 {object} response.ResponseSuccess "Member added successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Group or user not found"
+// @Router /v1/groups/{groupId}/members [post]
+func (h *GroupHandler) AddGroupMember(c fiber.Ctx) error {
+	groupId, err := strconv.Atoi(c.Params("groupId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid group ID", nil)
+	}
+
+	var body struct {
+		UserID int `json:"user_id" validate:"required"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	member, err := h.groupUsecase.AddMember(groupId, body.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Group or user not found", nil)
+		}
+		return response.Error(c, err.Error(), nil)
+	}
+
+	return response.Success(c, "Member added successfully", member, nil)
+}
+
+// RemoveGroupMember godoc
+// @Summary Remove a member from a group
+// @Description Remove a user from a group
+// @Tags Groups
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param groupId path int true "Group ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} response.ResponseSuccess "Member removed successfully"
+// @Failure 400 {object} response.ResponseError "Invalid ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Group member not found"
+// @Router /v1/groups/{groupId}/members/{userId} [delete]
+func (h *GroupHandler) RemoveGroupMember(c fiber.Ctx) error {
+	groupId, err := strconv.Atoi(c.Params("groupId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid group ID", nil)
+	}
+
+	userId, err := strconv.Atoi(c.Params("userId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid user ID", nil)
+	}
+
+	if err := h.groupUsecase.RemoveMember(groupId, userId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Group member not found", nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to remove member", nil)
+	}
+
+	return response.Success(c, "Member removed successfully", nil, nil)
+}
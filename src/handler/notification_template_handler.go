@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"technical-test/src/response"
+	"technical-test/src/usecase"
+	"technical-test/src/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type NotificationTemplateHandler struct {
+	templateUsecase usecase.NotificationTemplateUsecase
+}
+
+func NewNotificationTemplateHandler(templateUsecase usecase.NotificationTemplateUsecase) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		templateUsecase: templateUsecase,
+	}
+}
+
+// CreateNotificationTemplate godoc
+// @Summary Create a notification template
+// @Description Store the email template of a notification kind (STEP_ASSIGNED, REQUEST_APPROVED, REQUEST_REJECTED, REQUEST_RETURNED) for one workflow, or the global default when workflow_id is omitted. subject and text_body use Go text/template, html_body (optional) uses html/template; all are rendered with .Request, .Workflow, .Step, .Recipient, .Actor and .Comment
+// @Tags Notification Templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param body body object{kind=string,workflow_id=int,subject=string,text_body=string,html_body=string} true "Create Notification Template Request"
+// @Success 200 {object} response.ResponseSuccess "Notification template created successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Workflow not found"
+// @Failure 409 {object} response.ResponseError "A template for this kind and workflow already exists"
+// @Router /v1/notification-templates [post]
+func (h *NotificationTemplateHandler) CreateNotificationTemplate(c fiber.Ctx) error {
+	var body struct {
+		Kind       string `json:"kind" validate:"required"`
+		WorkflowID *uint  `json:"workflow_id"`
+		Subject    string `json:"subject" validate:"required"`
+		TextBody   string `json:"text_body" validate:"required"`
+		HTMLBody   string `json:"html_body"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	template, err := h.templateUsecase.CreateTemplate(body.Kind, body.WorkflowID, body.Subject, body.TextBody, body.HTMLBody, utils.GetUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Workflow not found", nil)
+		case errors.Is(err, usecase.ErrNotificationTemplateExists):
+			c.Status(fiber.StatusConflict)
+		case errors.Is(err, usecase.ErrInvalidNotificationKind), errors.Is(err, usecase.ErrInvalidNotificationTemplate):
+			c.Status(fiber.StatusBadRequest)
+		default:
+			c.Status(fiber.StatusInternalServerError)
+			return response.Error(c, "Failed to create notification template", nil)
+		}
+		return response.Error(c, err.Error(), nil)
+	}
+
+	setETag(c, template.Version)
+	return response.Success(c, "Notification template created successfully", template, nil)
+}
+
+// FindAllNotificationTemplates godoc
+// @Summary List notification templates
+// @Description Get the stored notification templates with pagination, optionally filtered by kind or workflow. Kinds without a stored template use the built-in text
+// @Tags Notification Templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param kind query string false "Filter by notification kind"
+// @Param workflow_id query int false "Filter by workflow ID"
+// @Success 200 {object} response.ResponseSuccess "Notification templates retrieved successfully"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 500 {object} response.ResponseError "Internal server error"
+// @Router /v1/notification-templates [get]
+func (h *NotificationTemplateHandler) FindAllNotificationTemplates(c fiber.Ctx) error {
+	params := utils.GetPaginationParams(c)
+	workflowID, _ := strconv.Atoi(c.Query("workflow_id"))
+
+	templates, total, err := h.templateUsecase.FindTemplatesWithPagination(params.Page, params.PageSize, c.Query("kind"), workflowID)
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve notification templates", nil)
+	}
+
+	totalPages := utils.CalculateTotalPages(total, params.PageSize)
+	meta := utils.PaginationMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	data := fiber.Map{
+		"notification_templates": templates,
+		"pagination":             meta,
+	}
+
+	return response.Success(c, "Notification templates retrieved successfully", data, nil)
+}
+
+// GetNotificationTemplateByID godoc
+// @Summary Get notification template by ID
+// @Description Retrieve a stored notification template
+// @Tags Notification Templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param templateId path int true "Notification Template ID"
+// @Success 200 {object} response.ResponseSuccess "Notification template retrieved successfully"
+// @Failure 400 {object} response.ResponseError "Invalid notification template ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Notification template not found"
+// @Header 200 {string} ETag "Current version, to send back in If-Match"
+// @Router /v1/notification-templates/{templateId} [get]
+func (h *NotificationTemplateHandler) GetNotificationTemplateByID(c fiber.Ctx) error {
+	templateId, err := strconv.Atoi(c.Params("templateId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid notification template ID", nil)
+	}
+
+	template, err := h.templateUsecase.GetTemplateByID(templateId)
+	if err != nil {
+		c.Status(fiber.StatusNotFound)
+		return response.Error(c, "Notification template not found", nil)
+	}
+
+	setETag(c, template.Version)
+	return response.Success(c, "Notification template retrieved successfully", template, nil)
+}
+
+// UpdateNotificationTemplate godoc
+// @Summary Update a notification template
+// @Description Replace the subject and bodies of a stored notification template. Its kind and workflow cannot change. If-Match must carry the ETag of the template; a stale version is refused instead of overwriting a concurrent change
+// @Tags Notification Templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param templateId path int true "Notification Template ID"
+// @Param If-Match header string true "ETag of the template from a previous GET"
+// @Param body body object{subject=string,text_body=string,html_body=string} true "Update Notification Template Request"
+// @Success 200 {object} response.ResponseSuccess "Notification template updated successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Notification template not found"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current version"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
+// @Router /v1/notification-templates/{templateId} [put]
+func (h *NotificationTemplateHandler) UpdateNotificationTemplate(c fiber.Ctx) error {
+	templateId, err := strconv.Atoi(c.Params("templateId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid notification template ID", nil)
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Status(preconditionStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	var body struct {
+		Subject  string `json:"subject" validate:"required"`
+		TextBody string `json:"text_body" validate:"required"`
+		HTMLBody string `json:"html_body"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	template, err := h.templateUsecase.UpdateTemplate(templateId, version, body.Subject, body.TextBody, body.HTMLBody)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Notification template not found", nil)
+		case errors.Is(err, usecase.ErrInvalidNotificationTemplate):
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, err.Error(), nil)
+		case errors.Is(err, usecase.ErrVersionMismatch):
+			c.Status(fiber.StatusPreconditionFailed)
+			return response.Error(c, err.Error(), nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to update notification template", nil)
+	}
+
+	setETag(c, template.Version)
+	return response.Success(c, "Notification template updated successfully", template, nil)
+}
+
+// DeleteNotificationTemplate godoc
+// @Summary Delete a notification template
+// @Description Remove a stored notification template. A deleted workflow override falls back to the global default, a deleted global default to the built-in text. If-Match must carry the ETag of the template
+// @Tags Notification Templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param templateId path int true "Notification Template ID"
+// @Param If-Match header string true "ETag of the template from a previous GET"
+// @Success 200 {object} response.ResponseSuccess "Notification template deleted successfully"
+// @Failure 400 {object} response.ResponseError "Invalid notification template ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Notification template not found"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current version"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
+// @Router /v1/notification-templates/{templateId} [delete]
+func (h *NotificationTemplateHandler) DeleteNotificationTemplate(c fiber.Ctx) error {
+	templateId, err := strconv.Atoi(c.Params("templateId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid notification template ID", nil)
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Status(preconditionStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	if err := h.templateUsecase.DeleteTemplate(templateId, version); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Notification template not found", nil)
+		case errors.Is(err, usecase.ErrVersionMismatch):
+			c.Status(fiber.StatusPreconditionFailed)
+			return response.Error(c, err.Error(), nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to delete notification template", nil)
+	}
+
+	return response.Success(c, "Notification template deleted successfully", nil, nil)
+}
+
+// PreviewNotificationTemplate godoc
+// @Summary Preview a notification template
+// @Description Render a template against a sample request of the workflow (or of a sample workflow when workflow_id is omitted) without saving or sending it. When subject, text_body and html_body are all omitted, the template currently used for the kind and workflow is rendered
+// @Tags Notification Templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param body body object{kind=string,workflow_id=int,subject=string,text_body=string,html_body=string} true "Preview Notification Template Request"
+// @Success 200 {object} response.ResponseSuccess "Notification template rendered successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Workflow not found"
+// @Router /v1/notification-templates/preview [post]
+func (h *NotificationTemplateHandler) PreviewNotificationTemplate(c fiber.Ctx) error {
+	var body struct {
+		Kind       string `json:"kind" validate:"required"`
+		WorkflowID *uint  `json:"workflow_id"`
+		Subject    string `json:"subject"`
+		TextBody   string `json:"text_body"`
+		HTMLBody   string `json:"html_body"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	message, err := h.templateUsecase.PreviewTemplate(body.Kind, body.WorkflowID, body.Subject, body.TextBody, body.HTMLBody)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Workflow not found", nil)
+		case errors.Is(err, usecase.ErrInvalidNotificationKind), errors.Is(err, usecase.ErrInvalidNotificationTemplate):
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, err.Error(), nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to render notification template", nil)
+	}
+
+	data := fiber.Map{
+		"to":        message.To,
+		"subject":   message.Subject,
+		"text_body": message.TextBody,
+		"html_body": message.HTMLBody,
+	}
+
+	return response.Success(c, "Notification template rendered successfully", data, nil)
+}
@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"strconv"
+	"technical-test/src/model"
 	"technical-test/src/response"
 	"technical-test/src/usecase"
 	"technical-test/src/utils"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type RequestHandler struct {
@@ -23,20 +29,25 @@ func NewRequestHandler(requestUsecase usecase.RequestUsecase, workflowUsecase us
 
 // CreateRequest godoc
 // @Summary Create a new request
-// @Description Submit a new request for a workflow
+// @Description Submit a new request for a workflow. Depending on the workflow submission_mode the amount is merged into the pending request of the workflow or creates a separate request; the applied mode is returned as submission_mode. amount is an exact decimal (number or string, at most 4 decimal places) in currency, which defaults to the workflow currency. Other currencies are converted to the workflow currency at the exchange rate valid on the submission date; the converted base_amount and the rate used are stored on the request
 // @Tags Requests
 // @Security Bearer
 // @Accept json
 // @Produce json
-// @Param body body object{workflow_id=int,amount=number} true "Create Request"
+// @Param body body object{workflow_id=int,amount=string,currency=string,metadata=object} true "Create Request"
+// @Param Idempotency-Key header string false "Key that makes retries of this call return the original response"
 // @Success 200 {object} response.ResponseSuccess "Request created successfully"
 // @Failure 400 {object} response.ResponseError "Validation error"
 // @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 409 {object} response.ResponseError "Idempotency key reused with a different request"
+// @Failure 422 {object} response.ResponseError "No exchange rate to the workflow currency"
 // @Router /v1/requests [post]
 func (h *RequestHandler) CreateRequest(c fiber.Ctx) error {
 	var body struct {
-		WorkflowID int     `json:"workflow_id" validate:"required"`
-		Amount     float64 `json:"amount" validate:"required,gt=0"`
+		WorkflowID int             `json:"workflow_id" validate:"required"`
+		Amount     decimal.Decimal `json:"amount"`
+		Currency   string          `json:"currency"`
+		Metadata   json.RawMessage `json:"metadata"`
 	}
 
 	if err := c.Bind().Body(&body); err != nil {
@@ -44,17 +55,23 @@ func (h *RequestHandler) CreateRequest(c fiber.Ctx) error {
 		return response.Error(c, utils.FormatValidationError(err), nil)
 	}
 
-	request, err := h.requestUsecase.CreateRequest(body.WorkflowID, body.Amount)
+	request, err := h.requestUsecase.CreateRequest(body.WorkflowID, body.Amount, body.Currency, datatypes.JSON(body.Metadata), utils.GetUserID(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidMetadata) || errors.Is(err, usecase.ErrInvalidExpression) {
+			c.Status(fiber.StatusBadRequest)
+		}
+		if errors.Is(err, usecase.ErrCurrencyMismatch) || errors.Is(err, usecase.ErrExchangeRateNotFound) {
+			c.Status(fiber.StatusUnprocessableEntity)
+		}
 		return response.Error(c, err.Error(), nil)
 	}
 
-	return response.Success(c, "Request created successfully", request, nil)
+	return response.Success(c, "Request created successfully", request, []interface{}{"submission_mode", request.SubmissionMode})
 }
 
 // FindAllRequests godoc
 // @Summary List all requests
-// @Description Get all requests with pagination and optional status filtering
+// @Description Get requests with pagination and optional status filtering. Admins see every request; other users see requests they submitted, decided on, or can act on
 // @Tags Requests
 // @Security Bearer
 // @Accept json
@@ -62,7 +79,8 @@ func (h *RequestHandler) CreateRequest(c fiber.Ctx) error {
 // @Param page query int false "Page number" default(1)
 // @Param pageSize query int false "Page size" default(10)
 // @Param search query string false "Search by request ID"
-// @Param status query string false "Filter by status (pending, approved, rejected)"
+// @Param status query string false "Filter by status (pending, approved, rejected, cancelled, returned)"
+// @Param mine query bool false "Only requests submitted by the caller"
 // @Success 200 {object} response.ResponseSuccess "Requests retrieved successfully"
 // @Failure 401 {object} response.ResponseError "Unauthorized"
 // @Failure 500 {object} response.ResponseError "Internal server error"
@@ -70,8 +88,9 @@ func (h *RequestHandler) CreateRequest(c fiber.Ctx) error {
 func (h *RequestHandler) FindAllRequests(c fiber.Ctx) error {
 	params := utils.GetPaginationParams(c)
 	status := c.Query("status")
+	mine := c.Query("mine") == "true"
 
-	requests, total, err := h.requestUsecase.FindAllRequestsWithPagination(params.Page, params.PageSize, params.Search, status)
+	requests, total, err := h.requestUsecase.FindAllRequestsWithPagination(params.Page, params.PageSize, params.Search, status, mine, requestViewer(c))
 	if err != nil {
 		c.Status(fiber.StatusInternalServerError)
 		return response.Error(c, "Failed to retrieve requests", nil)
@@ -93,9 +112,47 @@ func (h *RequestHandler) FindAllRequests(c fiber.Ctx) error {
 	return response.Success(c, "Requests retrieved successfully", data, nil)
 }
 
+// FindInbox godoc
+// @Summary List requests waiting on the caller
+// @Description Get the pending requests whose current step can be approved or rejected by the caller (as user, role or group member) and that the caller has not decided on yet, oldest first
+// @Tags Requests
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {object} response.ResponseSuccess "Inbox retrieved successfully"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 500 {object} response.ResponseError "Internal server error"
+// @Router /v1/requests/inbox [get]
+func (h *RequestHandler) FindInbox(c fiber.Ctx) error {
+	params := utils.GetPaginationParams(c)
+
+	requests, total, err := h.requestUsecase.FindInboxWithPagination(params.Page, params.PageSize, utils.GetUserID(c))
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve inbox", nil)
+	}
+
+	totalPages := utils.CalculateTotalPages(total, params.PageSize)
+	meta := utils.PaginationMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	data := fiber.Map{
+		"requests":   requests,
+		"pagination": meta,
+	}
+
+	return response.Success(c, "Inbox retrieved successfully", data, nil)
+}
+
 // GetRequestByID godoc
 // @Summary Get request by ID
-// @Description Retrieve a specific request by its ID
+// @Description Retrieve a specific request by its ID. Non-admin users only see requests they submitted, decided on, or can act on
 // @Tags Requests
 // @Security Bearer
 // @Accept json
@@ -105,6 +162,7 @@ func (h *RequestHandler) FindAllRequests(c fiber.Ctx) error {
 // @Failure 400 {object} response.ResponseError "Invalid request ID"
 // @Failure 401 {object} response.ResponseError "Unauthorized"
 // @Failure 404 {object} response.ResponseError "Request not found"
+// @Header 200 {string} ETag "Current version, to send back in If-Match"
 // @Router /v1/requests/{requestId} [get]
 func (h *RequestHandler) GetRequestByID(c fiber.Ctx) error {
 	requestId, err := strconv.Atoi(c.Params("requestId"))
@@ -113,27 +171,35 @@ func (h *RequestHandler) GetRequestByID(c fiber.Ctx) error {
 		return response.Error(c, "Invalid request ID", nil)
 	}
 
-	request, err := h.requestUsecase.GetRequestByID(requestId)
+	request, err := h.requestUsecase.GetRequestByID(requestId, requestViewer(c))
 	if err != nil {
 		c.Status(fiber.StatusNotFound)
 		return response.Error(c, "Request not found", nil)
 	}
 
+	setETag(c, request.Version)
 	return response.Success(c, "Request retrieved successfully", request, nil)
 }
 
 // ApproveRequest godoc
 // @Summary Approve a request
-// @Description Approve a pending request
+// @Description Approve the current step of a pending request. Once the step quorum is reached the request moves to the next step level, and becomes APPROVED once the last level is approved
 // @Tags Requests
 // @Security Bearer
 // @Accept json
 // @Produce json
 // @Param requestId path int true "Request ID"
+// @Param If-Match header string true "ETag of the request from a previous GET"
+// @Param body body object{comment=string} false "Approval comment"
+// @Param Idempotency-Key header string false "Key that makes retries of this call return the original response"
 // @Success 200 {object} response.ResponseSuccess "Request approved successfully"
 // @Failure 400 {object} response.ResponseError "Invalid request ID"
 // @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "User is not an allowed actor for the current step"
 // @Failure 404 {object} response.ResponseError "Request not found"
+// @Failure 409 {object} response.ResponseError "User has already approved this step, or idempotency key reused with a different request"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current version"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
 // @Router /v1/requests/{requestId}/approve [post]
 func (h *RequestHandler) ApproveRequest(c fiber.Ctx) error {
 	requestId, err := strconv.Atoi(c.Params("requestId"))
@@ -142,26 +208,51 @@ func (h *RequestHandler) ApproveRequest(c fiber.Ctx) error {
 		return response.Error(c, "Invalid request ID", nil)
 	}
 
-	request, err := h.requestUsecase.ApproveRequest(requestId)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Status(preconditionStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	var body struct {
+		Comment string `json:"comment"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&body); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, utils.FormatValidationError(err), nil)
+		}
+	}
+
+	request, err := h.requestUsecase.ApproveRequest(requestId, version, utils.GetUserID(c), body.Comment)
 	if err != nil {
+		c.Status(requestErrorStatus(err))
 		return response.Error(c, err.Error(), nil)
 	}
 
+	setETag(c, request.Version)
 	return response.Success(c, "Request approved successfully", request, nil)
 }
 
 // RejectRequest godoc
 // @Summary Reject a request
-// @Description Reject a pending request
+// @Description Reject a pending request. Steps with "reason_required" in their conditions refuse a rejection without a reason; the reason is stored on the request
 // @Tags Requests
 // @Security Bearer
 // @Accept json
 // @Produce json
 // @Param requestId path int true "Request ID"
+// @Param If-Match header string true "ETag of the request from a previous GET"
+// @Param body body object{reason=string,comment=string} false "Rejection reason and comment"
+// @Param Idempotency-Key header string false "Key that makes retries of this call return the original response"
 // @Success 200 {object} response.ResponseSuccess "Request rejected successfully"
-// @Failure 400 {object} response.ResponseError "Invalid request ID"
+// @Failure 400 {object} response.ResponseError "Invalid request ID or missing reason"
 // @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "User is not an allowed actor for the current step"
 // @Failure 404 {object} response.ResponseError "Request not found"
+// @Failure 409 {object} response.ResponseError "Idempotency key reused with a different request"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current version"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
 // @Router /v1/requests/{requestId}/reject [post]
 func (h *RequestHandler) RejectRequest(c fiber.Ctx) error {
 	requestId, err := strconv.Atoi(c.Params("requestId"))
@@ -170,10 +261,355 @@ func (h *RequestHandler) RejectRequest(c fiber.Ctx) error {
 		return response.Error(c, "Invalid request ID", nil)
 	}
 
-	request, err := h.requestUsecase.RejectRequest(requestId)
+	version, err := ifMatchVersion(c)
 	if err != nil {
+		c.Status(preconditionStatus(err))
 		return response.Error(c, err.Error(), nil)
 	}
 
+	var body struct {
+		Reason  string `json:"reason"`
+		Comment string `json:"comment"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&body); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, utils.FormatValidationError(err), nil)
+		}
+	}
+
+	request, err := h.requestUsecase.RejectRequest(requestId, version, utils.GetUserID(c), body.Reason, body.Comment)
+	if err != nil {
+		c.Status(requestErrorStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	setETag(c, request.Version)
 	return response.Success(c, "Request rejected successfully", request, nil)
 }
+
+// BulkApproveRequests godoc
+// @Summary Approve several requests at once
+// @Description Approve up to 100 requests with one call. Every request goes through the same checks as a single approve, in its own transaction, and the response lists the outcome per request (ok, below_threshold, version_mismatch, not_pending, forbidden, not_found or failed) so one bad item does not abort the batch. Pass "items" with the version from each request's ETag to guard against concurrent changes; IDs in "ids" (or items without a version) are decided on whatever state the request has when it is locked
+// @Tags Requests
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param body body object{ids=[]int,items=[]usecase.BulkItem,comment=string} true "Request IDs and/or items with versions, and an optional comment"
+// @Param Idempotency-Key header string false "Key that makes retries of this call return the original response"
+// @Success 200 {object} response.ResponseSuccess "Bulk approve processed"
+// @Failure 400 {object} response.ResponseError "Empty or too many request IDs"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 409 {object} response.ResponseError "Idempotency key reused with a different request"
+// @Router /v1/requests/bulk-approve [post]
+func (h *RequestHandler) BulkApproveRequests(c fiber.Ctx) error {
+	var body struct {
+		IDs     []int              `json:"ids"`
+		Items   []usecase.BulkItem `json:"items"`
+		Comment string             `json:"comment"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	results, err := h.requestUsecase.BulkApproveRequests(bulkItems(body.Items, body.IDs), utils.GetUserID(c), body.Comment)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, err.Error(), nil)
+	}
+
+	return response.Success(c, "Bulk approve processed", bulkResponse(results), nil)
+}
+
+// BulkRejectRequests godoc
+// @Summary Reject several requests at once
+// @Description Reject up to 100 requests with one reason and comment. Every request goes through the same checks as a single reject, in its own transaction, and the response lists the outcome per request (ok, version_mismatch, not_pending, forbidden, not_found or failed). Pass "items" with the version from each request's ETag to guard against concurrent changes; IDs in "ids" (or items without a version) are decided on whatever state the request has when it is locked
+// @Tags Requests
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param body body object{ids=[]int,items=[]usecase.BulkItem,reason=string,comment=string} true "Request IDs and/or items with versions, rejection reason and an optional comment"
+// @Param Idempotency-Key header string false "Key that makes retries of this call return the original response"
+// @Success 200 {object} response.ResponseSuccess "Bulk reject processed"
+// @Failure 400 {object} response.ResponseError "Empty or too many request IDs"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 409 {object} response.ResponseError "Idempotency key reused with a different request"
+// @Router /v1/requests/bulk-reject [post]
+func (h *RequestHandler) BulkRejectRequests(c fiber.Ctx) error {
+	var body struct {
+		IDs     []int              `json:"ids"`
+		Items   []usecase.BulkItem `json:"items"`
+		Reason  string             `json:"reason"`
+		Comment string             `json:"comment"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	results, err := h.requestUsecase.BulkRejectRequests(bulkItems(body.Items, body.IDs), utils.GetUserID(c), body.Reason, body.Comment)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, err.Error(), nil)
+	}
+
+	return response.Success(c, "Bulk reject processed", bulkResponse(results), nil)
+}
+
+// bulkItems merges the versioned items and the plain IDs of a bulk body. Plain
+// IDs carry no version, so they are not checked against If-Match style
+// versions.
+func bulkItems(items []usecase.BulkItem, ids []int) []usecase.BulkItem {
+	merged := append([]usecase.BulkItem(nil), items...)
+	for _, id := range ids {
+		merged = append(merged, usecase.BulkItem{ID: id})
+	}
+	return merged
+}
+
+// bulkResponse wraps bulk results with a count per outcome.
+func bulkResponse(results []usecase.BulkResult) fiber.Map {
+	summary := map[string]int{}
+	for _, result := range results {
+		summary[result.Result]++
+	}
+	return fiber.Map{
+		"results": results,
+		"summary": summary,
+	}
+}
+
+// CancelRequest godoc
+// @Summary Cancel a request
+// @Description Withdraw a pending request. Only the user who submitted the request can cancel it
+// @Tags Requests
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param requestId path int true "Request ID"
+// @Param If-Match header string true "ETag of the request from a previous GET"
+// @Success 200 {object} response.ResponseSuccess "Request cancelled successfully"
+// @Failure 400 {object} response.ResponseError "Request is not pending"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "User is not the requester"
+// @Failure 404 {object} response.ResponseError "Request not found"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current version"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
+// @Router /v1/requests/{requestId}/cancel [post]
+func (h *RequestHandler) CancelRequest(c fiber.Ctx) error {
+	requestId, err := strconv.Atoi(c.Params("requestId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid request ID", nil)
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Status(preconditionStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	request, err := h.requestUsecase.CancelRequest(requestId, version, utils.GetUserID(c))
+	if err != nil {
+		c.Status(requestErrorStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	setETag(c, request.Version)
+	return response.Success(c, "Request cancelled successfully", request, nil)
+}
+
+// ReturnRequest godoc
+// @Summary Send a request back for revision
+// @Description Return a pending request instead of rejecting it. With target "requester" the request becomes RETURNED until the requester resubmits it, restarting at "level" (default 1). With target "level" the request stays PENDING and moves back to the given earlier level. Decisions from the restart level onwards no longer count
+// @Tags Requests
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param requestId path int true "Request ID"
+// @Param If-Match header string true "ETag of the request from a previous GET"
+// @Param body body object{target=string,level=int,comment=string} true "Return Request"
+// @Success 200 {object} response.ResponseSuccess "Request returned successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "User is not an allowed actor for the current step"
+// @Failure 404 {object} response.ResponseError "Request not found"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current version"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
+// @Router /v1/requests/{requestId}/return [post]
+func (h *RequestHandler) ReturnRequest(c fiber.Ctx) error {
+	requestId, err := strconv.Atoi(c.Params("requestId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid request ID", nil)
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Status(preconditionStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	var body struct {
+		Target  string `json:"target" validate:"required,oneof=requester level"`
+		Level   uint   `json:"level"`
+		Comment string `json:"comment"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	request, err := h.requestUsecase.ReturnRequest(requestId, version, utils.GetUserID(c), body.Target, body.Level, body.Comment)
+	if err != nil {
+		c.Status(requestErrorStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	setETag(c, request.Version)
+	return response.Success(c, "Request returned successfully", request, nil)
+}
+
+// ResubmitRequest godoc
+// @Summary Resubmit a returned request
+// @Description Edit the amount or metadata of a RETURNED request and send it back through the steps from the level chosen on return. Only the requester can resubmit
+// @Tags Requests
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param requestId path int true "Request ID"
+// @Param If-Match header string true "ETag of the request from a previous GET"
+// @Param body body object{amount=string,metadata=object} false "Resubmit Request"
+// @Success 200 {object} response.ResponseSuccess "Request resubmitted successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "User is not the requester"
+// @Failure 404 {object} response.ResponseError "Request not found"
+// @Failure 409 {object} response.ResponseError "Request has not been returned"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current version"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
+// @Router /v1/requests/{requestId}/resubmit [post]
+func (h *RequestHandler) ResubmitRequest(c fiber.Ctx) error {
+	requestId, err := strconv.Atoi(c.Params("requestId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid request ID", nil)
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Status(preconditionStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	var body struct {
+		Amount   *decimal.Decimal `json:"amount"`
+		Metadata json.RawMessage  `json:"metadata"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&body); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, utils.FormatValidationError(err), nil)
+		}
+	}
+
+	request, err := h.requestUsecase.ResubmitRequest(requestId, version, utils.GetUserID(c), body.Amount, datatypes.JSON(body.Metadata))
+	if err != nil {
+		c.Status(requestErrorStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
+	setETag(c, request.Version)
+	return response.Success(c, "Request resubmitted successfully", request, nil)
+}
+
+// FindApprovalsByRequestID godoc
+// @Summary List approvals of a request
+// @Description Get the approval decisions recorded for a request, including who approved or rejected each step
+// @Tags Requests
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param requestId path int true "Request ID"
+// @Success 200 {object} response.ResponseSuccess "Approvals retrieved successfully"
+// @Failure 400 {object} response.ResponseError "Invalid request ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Request not found"
+// @Router /v1/requests/{requestId}/approvals [get]
+func (h *RequestHandler) FindApprovalsByRequestID(c fiber.Ctx) error {
+	requestId, err := strconv.Atoi(c.Params("requestId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid request ID", nil)
+	}
+
+	approvals, err := h.requestUsecase.FindApprovalsByRequestID(requestId, requestViewer(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Request not found", nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve approvals", nil)
+	}
+
+	return response.Success(c, "Approvals retrieved successfully", approvals, nil)
+}
+
+// FindHistoryByRequestID godoc
+// @Summary Get the history of a request
+// @Description Get the timeline of a request: creation, merged amounts, step changes and the final decision, each with the acting user and before/after values
+// @Tags Requests
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param requestId path int true "Request ID"
+// @Success 200 {object} response.ResponseSuccess "Request history retrieved successfully"
+// @Failure 400 {object} response.ResponseError "Invalid request ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Request not found"
+// @Router /v1/requests/{requestId}/history [get]
+func (h *RequestHandler) FindHistoryByRequestID(c fiber.Ctx) error {
+	requestId, err := strconv.Atoi(c.Params("requestId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid request ID", nil)
+	}
+
+	events, err := h.requestUsecase.FindHistoryByRequestID(requestId, requestViewer(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Request not found", nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve request history", nil)
+	}
+
+	return response.Success(c, "Request history retrieved successfully", events, nil)
+}
+
+func requestViewer(c fiber.Ctx) usecase.Viewer {
+	return usecase.Viewer{
+		UserID: utils.GetUserID(c),
+		Admin:  utils.HasAnyRole(c, model.RoleAdmin),
+	}
+}
+
+// requestErrorStatus maps request usecase errors to HTTP status codes.
+func requestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrActorForbidden), errors.Is(err, usecase.ErrNotRequester):
+		return fiber.StatusForbidden
+	case errors.Is(err, usecase.ErrDuplicateApproval), errors.Is(err, usecase.ErrRequestNotReturned):
+		return fiber.StatusConflict
+	case errors.Is(err, usecase.ErrVersionMismatch):
+		return fiber.StatusPreconditionFailed
+	case errors.Is(err, usecase.ErrCurrencyMismatch), errors.Is(err, usecase.ErrExchangeRateNotFound):
+		return fiber.StatusUnprocessableEntity
+	}
+	return fiber.StatusBadRequest
+}
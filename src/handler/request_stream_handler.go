@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"technical-test/src/response"
+	"technical-test/src/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// streamKeepAlive is how long a stream may stay silent before a comment line
+// is sent, so proxies do not close idle connections.
+const streamKeepAlive = 15 * time.Second
+
+// StreamRequestEvents godoc
+// @Summary Stream request events
+// @Description Server-Sent Events stream of the request events the caller may see (CREATED, AMOUNT_MERGED, STEP_ADVANCED, APPROVED, REJECTED, ...). Each message has the request event ID as id, the lower-case event type as event and the event as JSON data. After a reconnect, events recorded after the Last-Event-ID header (or last_event_id query) are sent first (at most 1000; when more were missed a reset event follows, whose id is the latest event, and the client should reload its requests)
+// @Tags Requests
+// @Security Bearer
+// @Produce text/event-stream
+// @Param workflow_id query int false "Only events of this workflow"
+// @Param status query string false "Only events leaving the request in this status"
+// @Param assigned query bool false "Only requests pending on a step the caller can act on"
+// @Param Last-Event-ID header int false "ID of the last event received"
+// @Param last_event_id query int false "ID of the last event received, for clients that cannot set headers"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} response.ResponseError "Invalid parameter"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Router /v1/requests/stream [get]
+func (h *RequestHandler) StreamRequestEvents(c fiber.Ctx) error {
+	var filter usecase.StreamFilter
+
+	if value := c.Query("workflow_id"); value != "" {
+		workflowID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, "Invalid workflow ID", nil)
+		}
+		filter.WorkflowID = uint(workflowID)
+	}
+	filter.Status = c.Query("status")
+	filter.AssignedToMe = c.Query("assigned") == "true"
+
+	lastEventID := c.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var resumeFrom uint64
+	if lastEventID != "" {
+		var err error
+		if resumeFrom, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, "Invalid Last-Event-ID", nil)
+		}
+	}
+
+	stream, err := h.requestUsecase.OpenRequestStream(uint(resumeFrom), filter, requestViewer(c))
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to open request stream", nil)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		defer stream.Close()
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			message, ok, err := stream.Next(streamKeepAlive)
+			if err != nil {
+				// The client reconnects and resumes from the last event ID.
+				return
+			}
+
+			if ok {
+				data, err := json.Marshal(message)
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", message.EventID, strings.ToLower(message.Type), data)
+			} else {
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"technical-test/src/response"
+	"technical-test/src/usecase"
+	"technical-test/src/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type RoleHandler struct {
+	roleUsecase usecase.RoleUsecase
+}
+
+func NewRoleHandler(roleUsecase usecase.RoleUsecase) *RoleHandler {
+	return &RoleHandler{
+		roleUsecase: roleUsecase,
+	}
+}
+
+// CreateRole godoc
+// @Summary Create a custom role
+// @Description Create a role such as Manager or CFO that can be assigned to users and used as a step actor (admin only)
+// @Tags Roles
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param body body object{name=string} true "Create Role Request"
+// @Success 200 {object} response.ResponseSuccess{data=model.Role} "Role created successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "Forbidden"
+// @Router /v1/roles [post]
+func (h *RoleHandler) CreateRole(c fiber.Ctx) error {
+	var body struct {
+		Name string `json:"name" validate:"required"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	role, err := h.roleUsecase.CreateRole(body.Name, utils.GetUserID(c))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, err.Error(), nil)
+	}
+
+	return response.Success(c, "Role created successfully", role, nil)
+}
+
+// FindAllRoles godoc
+// @Summary List custom roles
+// @Description Get the roles created by admins with pagination support. The built-in roles are not listed (admin only)
+// @Tags Roles
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param search query string false "Search by role name"
+// @Success 200 {object} response.ResponseSuccess "Roles retrieved successfully"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "Forbidden"
+// @Failure 500 {object} response.ResponseError "Internal server error"
+// @Router /v1/roles [get]
+func (h *RoleHandler) FindAllRoles(c fiber.Ctx) error {
+	params := utils.GetPaginationParams(c)
+
+	roles, total, err := h.roleUsecase.FindAllRolesWithPagination(params.Page, params.PageSize, params.Search)
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve roles", nil)
+	}
+
+	totalPages := utils.CalculateTotalPages(total, params.PageSize)
+	meta := utils.PaginationMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	data := fiber.Map{
+		"roles":      roles,
+		"pagination": meta,
+	}
+
+	return response.Success(c, "Roles retrieved successfully", data, nil)
+}
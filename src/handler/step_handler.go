@@ -2,13 +2,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
+	"technical-test/src/model"
 	"technical-test/src/response"
 	"technical-test/src/usecase"
 	"technical-test/src/utils"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type StepHandler struct {
@@ -31,11 +34,15 @@ func NewStepHandler(stepUsecase usecase.StepUsecase, workflowUsecase usecase.Wor
 // @Accept json
 // @Produce json
 // @Param workflowId path int true "Workflow ID"
-// @Param body body object{actor=string,conditions=object} true "Create Step Request"
+// @Param If-Match header string true "ETag of the workflow from a previous GET"
+// @Param body body object{actor=string,conditions=object} true "Create Step Request (actor: user:<id>, role:<name>, group:<name> or a role name)"
 // @Success 200 {object} response.ResponseSuccess "Step created successfully"
 // @Failure 400 {object} response.ResponseError "Validation error"
 // @Failure 401 {object} response.ResponseError "Unauthorized"
 // @Failure 404 {object} response.ResponseError "Workflow not found"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current workflow version"
+// @Failure 422 {object} response.ResponseError "Invalid conditions"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
 // @Failure 500 {object} response.ResponseError "Internal server error"
 // @Router /v1/workflows/{workflowId}/steps [post]
 func (h *StepHandler) CreateStep(c fiber.Ctx) error {
@@ -50,6 +57,12 @@ func (h *StepHandler) CreateStep(c fiber.Ctx) error {
 		return response.Error(c, "Workflow not found", nil)
 	}
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Status(preconditionStatus(err))
+		return response.Error(c, err.Error(), nil)
+	}
+
 	var body struct {
 		Actor      string          `json:"actor" validate:"required"`
 		Conditions json.RawMessage `json:"conditions"`
@@ -68,9 +81,9 @@ func (h *StepHandler) CreateStep(c fiber.Ctx) error {
 		conditionsJSON = datatypes.JSON(body.Conditions)
 	}
 
-	step, err := h.stepUsecase.CreateStep(workflowId, body.Actor, conditionsJSON)
+	step, err := h.stepUsecase.CreateStep(workflowId, version, body.Actor, conditionsJSON)
 	if err != nil {
-		c.Status(fiber.StatusInternalServerError)
+		c.Status(stepErrorStatus(err))
 		return response.Error(c, err.Error(), nil)
 	}
 
@@ -130,27 +143,61 @@ func (h *StepHandler) FindStepsByWorkflowID(c fiber.Ctx) error {
 	return response.Success(c, "Steps retrieved successfully", data, nil)
 }
 
+// FindStepByID godoc
+// @Summary Get a step by ID
+// @Description Get a single step of a workflow. The step version is returned in the ETag header
+// @Tags Steps
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param workflowId path int true "Workflow ID"
+// @Param stepId path int true "Step ID"
+// @Success 200 {object} response.ResponseSuccess "Step retrieved successfully"
+// @Failure 400 {object} response.ResponseError "Invalid step ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Step not found"
+// @Header 200 {string} ETag "Current version, to send back in If-Match"
+// @Router /v1/workflows/{workflowId}/steps/{stepId} [get]
 func (h *StepHandler) FindStepByID(c fiber.Ctx) error {
-	stepId, err := strconv.Atoi(c.Params("stepId"))
-	if err != nil {
-		c.Status(fiber.StatusBadRequest)
-		return response.Error(c, "Invalid step ID", nil)
-	}
-
-	step, err := h.stepUsecase.GetStepByID(stepId)
-	if err != nil {
-		c.Status(fiber.StatusNotFound)
-		return response.Error(c, "Step not found", nil)
+	step, ok, err := h.workflowStep(c)
+	if !ok {
+		return err
 	}
 
+	setETag(c, step.Version)
 	return response.Success(c, "Step retrieved successfully", step, nil)
 }
 
+// UpdateStep godoc
+// @Summary Update a step
+// @Description Replace the level, actor and conditions of a step. If-Match must carry the ETag of the step; a stale version is refused instead of overwriting a concurrent change
+// @Tags Steps
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param workflowId path int true "Workflow ID"
+// @Param stepId path int true "Step ID"
+// @Param If-Match header string true "ETag of the step from a previous GET"
+// @Param body body object{level=int,actor=string,conditions=object} true "Update Step Request"
+// @Success 200 {object} response.ResponseSuccess "Step updated successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Step not found"
+// @Failure 412 {object} response.ResponseError "If-Match does not match the current version"
+// @Failure 422 {object} response.ResponseError "Invalid conditions"
+// @Failure 428 {object} response.ResponseError "If-Match header is required"
+// @Failure 500 {object} response.ResponseError "Internal server error"
+// @Router /v1/workflows/{workflowId}/steps/{stepId} [put]
 func (h *StepHandler) UpdateStep(c fiber.Ctx) error {
-	stepId, err := strconv.Atoi(c.Params("stepId"))
+	current, ok, err := h.workflowStep(c)
+	if !ok {
+		return err
+	}
+
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		c.Status(fiber.StatusBadRequest)
-		return response.Error(c, "Invalid step ID", nil)
+		c.Status(preconditionStatus(err))
+		return response.Error(c, err.Error(), nil)
 	}
 
 	var body struct {
@@ -172,11 +219,56 @@ func (h *StepHandler) UpdateStep(c fiber.Ctx) error {
 		conditionsJSON = datatypes.JSON(body.Conditions)
 	}
 
-	step, err := h.stepUsecase.UpdateStep(stepId, body.Level, body.Actor, conditionsJSON)
+	step, err := h.stepUsecase.UpdateStep(int(current.ID), version, body.Level, body.Actor, conditionsJSON)
 	if err != nil {
+		if status := stepErrorStatus(err); status != fiber.StatusInternalServerError {
+			c.Status(status)
+			return response.Error(c, err.Error(), nil)
+		}
 		c.Status(fiber.StatusInternalServerError)
 		return response.Error(c, "Failed to update step", nil)
 	}
 
+	setETag(c, step.Version)
 	return response.Success(c, "Step updated successfully", step, nil)
 }
+
+// workflowStep loads the step addressed by the route and makes sure it belongs
+// to the workflow in the path. When ok is false the error response has already
+// been written and err is what the handler should return.
+func (h *StepHandler) workflowStep(c fiber.Ctx) (step model.Step, ok bool, err error) {
+	workflowId, err := strconv.Atoi(c.Params("workflowId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return step, false, response.Error(c, "Invalid workflow ID", nil)
+	}
+	stepId, err := strconv.Atoi(c.Params("stepId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return step, false, response.Error(c, "Invalid step ID", nil)
+	}
+
+	step, err = h.stepUsecase.GetStepByID(stepId)
+	if err != nil || step.WorkflowID != uint(workflowId) {
+		c.Status(fiber.StatusNotFound)
+		return step, false, response.Error(c, "Step not found", nil)
+	}
+	return step, true, nil
+}
+
+// stepErrorStatus maps step usecase errors to HTTP status codes.
+func stepErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidActor), errors.Is(err, usecase.ErrActorNotFound):
+		return fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrInvalidConditions), errors.Is(err, usecase.ErrInvalidQuorum),
+		errors.Is(err, usecase.ErrQuorumUnreachable), errors.Is(err, usecase.ErrInvalidExpression), errors.Is(err, usecase.ErrInvalidCurrency),
+		errors.Is(err, usecase.ErrCurrencyMismatch):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrVersionMismatch):
+		return fiber.StatusPreconditionFailed
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusInternalServerError
+}
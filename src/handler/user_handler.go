@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"technical-test/src/model"
+	"technical-test/src/response"
+	"technical-test/src/usecase"
+	"technical-test/src/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type UserHandler struct {
+	userUsecase usecase.UserUsecase
+}
+
+func NewUserHandler(userUsecase usecase.UserUsecase) *UserHandler {
+	return &UserHandler{
+		userUsecase: userUsecase,
+	}
+}
+
+// FindAllUsers godoc
+// @Summary List all users
+// @Description Get all users and their roles with pagination support (admin only)
+// @Tags Users
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param search query string false "Search by name or email"
+// @Success 200 {object} response.ResponseSuccess "Users retrieved successfully"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "Forbidden"
+// @Failure 500 {object} response.ResponseError "Internal server error"
+// @Router /v1/users [get]
+func (h *UserHandler) FindAllUsers(c fiber.Ctx) error {
+	params := utils.GetPaginationParams(c)
+
+	users, total, err := h.userUsecase.FindAllUsersWithPagination(params.Page, params.PageSize, params.Search)
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve users", nil)
+	}
+
+	totalPages := utils.CalculateTotalPages(total, params.PageSize)
+	meta := utils.PaginationMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	data := fiber.Map{
+		"users":      toUserResponses(users),
+		"pagination": meta,
+	}
+
+	return response.Success(c, "Users retrieved successfully", data, nil)
+}
+
+// GetUserByID godoc
+// @Summary Get user by ID
+// @Description Retrieve a user and its roles (admin only)
+// @Tags Users
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param userId path int true "User ID"
+// @Success 200 {object} response.ResponseSuccess{data=response.UserResponse} "User retrieved successfully"
+// @Failure 400 {object} response.ResponseError "Invalid user ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "Forbidden"
+// @Failure 404 {object} response.ResponseError "User not found"
+// @Router /v1/users/{userId} [get]
+func (h *UserHandler) GetUserByID(c fiber.Ctx) error {
+	userId, err := strconv.Atoi(c.Params("userId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid user ID", nil)
+	}
+
+	user, err := h.userUsecase.GetUserByID(userId)
+	if err != nil {
+		c.Status(fiber.StatusNotFound)
+		return response.Error(c, "User not found", nil)
+	}
+
+	return response.Success(c, "User retrieved successfully", toUserResponse(user), nil)
+}
+
+// UpdateUserRoles godoc
+// @Summary Update user roles
+// @Description Replace the roles of a user (admin only). Roles take effect on the next login
+// @Tags Users
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param userId path int true "User ID"
+// @Param body body object{roles=[]string} true "Update Roles Request"
+// @Success 200 {object} response.ResponseSuccess{data=response.UserResponse} "User roles updated successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 403 {object} response.ResponseError "Forbidden"
+// @Failure 404 {object} response.ResponseError "User not found"
+// @Router /v1/users/{userId}/roles [put]
+func (h *UserHandler) UpdateUserRoles(c fiber.Ctx) error {
+	userId, err := strconv.Atoi(c.Params("userId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid user ID", nil)
+	}
+
+	var body struct {
+		Roles []string `json:"roles"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid request body", nil)
+	}
+
+	user, err := h.userUsecase.UpdateUserRoles(userId, body.Roles)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "User not found", nil)
+		}
+		return response.Error(c, err.Error(), nil)
+	}
+
+	return response.Success(c, "User roles updated successfully", toUserResponse(user), nil)
+}
+
+func toUserResponse(user model.User) response.UserResponse {
+	return response.UserResponse{
+		ID:    user.ID,
+		Name:  user.Name,
+		Email: user.Email,
+		Roles: user.RoleNames(),
+	}
+}
+
+func toUserResponses(users []model.User) []response.UserResponse {
+	result := make([]response.UserResponse, 0, len(users))
+	for _, user := range users {
+		result = append(result, toUserResponse(user))
+	}
+	return result
+}
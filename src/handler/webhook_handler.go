@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"technical-test/src/response"
+	"technical-test/src/usecase"
+	"technical-test/src/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	webhookUsecase usecase.WebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUsecase: webhookUsecase,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook subscription
+// @Description Subscribe a URL to request events of one workflow, or of every workflow when workflow_id is omitted. Events default to CREATED, STEP_ADVANCED, APPROVED, REJECTED and CANCELLED. Calls are signed with X-Webhook-Signature: sha256=HMAC-SHA256(secret, X-Webhook-Timestamp + "." + body). The secret is generated when omitted and is only returned in this response
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param body body object{url=string,secret=string,workflow_id=int,events=[]string} true "Create Webhook Request"
+// @Success 200 {object} response.ResponseSuccess "Webhook created successfully"
+// @Failure 400 {object} response.ResponseError "Validation error"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Workflow not found"
+// @Router /v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
+	var body struct {
+		URL        string   `json:"url" validate:"required"`
+		Secret     string   `json:"secret"`
+		WorkflowID *uint    `json:"workflow_id"`
+		Events     []string `json:"events"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, utils.FormatValidationError(err), nil)
+	}
+
+	subscription, secret, err := h.webhookUsecase.CreateSubscription(body.URL, body.Secret, body.WorkflowID, body.Events, utils.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Workflow not found", nil)
+		}
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, err.Error(), nil)
+	}
+
+	data := fiber.Map{
+		"webhook": subscription,
+		"secret":  secret,
+	}
+
+	return response.Success(c, "Webhook created successfully", data, nil)
+}
+
+// FindAllWebhooks godoc
+// @Summary List webhook subscriptions
+// @Description Get webhook subscriptions with pagination, optionally only those of one workflow
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param workflow_id query int false "Filter by workflow ID"
+// @Success 200 {object} response.ResponseSuccess "Webhooks retrieved successfully"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 500 {object} response.ResponseError "Internal server error"
+// @Router /v1/webhooks [get]
+func (h *WebhookHandler) FindAllWebhooks(c fiber.Ctx) error {
+	params := utils.GetPaginationParams(c)
+	workflowID, _ := strconv.Atoi(c.Query("workflow_id"))
+
+	subscriptions, total, err := h.webhookUsecase.FindSubscriptionsWithPagination(params.Page, params.PageSize, workflowID)
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve webhooks", nil)
+	}
+
+	totalPages := utils.CalculateTotalPages(total, params.PageSize)
+	meta := utils.PaginationMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	data := fiber.Map{
+		"webhooks":   subscriptions,
+		"pagination": meta,
+	}
+
+	return response.Success(c, "Webhooks retrieved successfully", data, nil)
+}
+
+// GetWebhookByID godoc
+// @Summary Get webhook subscription by ID
+// @Description Retrieve a webhook subscription. The secret is never returned
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "Webhook ID"
+// @Success 200 {object} response.ResponseSuccess "Webhook retrieved successfully"
+// @Failure 400 {object} response.ResponseError "Invalid webhook ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Webhook not found"
+// @Router /v1/webhooks/{webhookId} [get]
+func (h *WebhookHandler) GetWebhookByID(c fiber.Ctx) error {
+	webhookId, err := strconv.Atoi(c.Params("webhookId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid webhook ID", nil)
+	}
+
+	subscription, err := h.webhookUsecase.GetSubscriptionByID(webhookId)
+	if err != nil {
+		c.Status(fiber.StatusNotFound)
+		return response.Error(c, "Webhook not found", nil)
+	}
+
+	return response.Success(c, "Webhook retrieved successfully", subscription, nil)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook subscription
+// @Description Remove a webhook subscription together with its delivery log
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "Webhook ID"
+// @Success 200 {object} response.ResponseSuccess "Webhook deleted successfully"
+// @Failure 400 {object} response.ResponseError "Invalid webhook ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Webhook not found"
+// @Router /v1/webhooks/{webhookId} [delete]
+func (h *WebhookHandler) DeleteWebhook(c fiber.Ctx) error {
+	webhookId, err := strconv.Atoi(c.Params("webhookId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid webhook ID", nil)
+	}
+
+	if err := h.webhookUsecase.DeleteSubscription(webhookId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Webhook not found", nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to delete webhook", nil)
+	}
+
+	return response.Success(c, "Webhook deleted successfully", nil, nil)
+}
+
+// FindWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Get the delivery log of a webhook subscription, newest first, with the status, attempts and latest response of each delivery
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "Webhook ID"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param status query string false "Filter by status (PENDING, DELIVERED, FAILED)"
+// @Success 200 {object} response.ResponseSuccess "Webhook deliveries retrieved successfully"
+// @Failure 400 {object} response.ResponseError "Invalid webhook ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Webhook not found"
+// @Router /v1/webhooks/{webhookId}/deliveries [get]
+func (h *WebhookHandler) FindWebhookDeliveries(c fiber.Ctx) error {
+	webhookId, err := strconv.Atoi(c.Params("webhookId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid webhook ID", nil)
+	}
+
+	params := utils.GetPaginationParams(c)
+
+	deliveries, total, err := h.webhookUsecase.FindDeliveriesWithPagination(webhookId, params.Page, params.PageSize, c.Query("status"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Webhook not found", nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to retrieve webhook deliveries", nil)
+	}
+
+	totalPages := utils.CalculateTotalPages(total, params.PageSize)
+	meta := utils.PaginationMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	data := fiber.Map{
+		"deliveries": deliveries,
+		"pagination": meta,
+	}
+
+	return response.Success(c, "Webhook deliveries retrieved successfully", data, nil)
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a webhook
+// @Description Send the payload of a logged delivery again right away. The call is logged as a new delivery and retried with backoff if it fails
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} response.ResponseSuccess "Webhook redelivered"
+// @Failure 400 {object} response.ResponseError "Invalid ID"
+// @Failure 401 {object} response.ResponseError "Unauthorized"
+// @Failure 404 {object} response.ResponseError "Webhook delivery not found"
+// @Router /v1/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(c fiber.Ctx) error {
+	webhookId, err := strconv.Atoi(c.Params("webhookId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid webhook ID", nil)
+	}
+
+	deliveryId, err := strconv.Atoi(c.Params("deliveryId"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return response.Error(c, "Invalid delivery ID", nil)
+	}
+
+	delivery, err := h.webhookUsecase.Redeliver(webhookId, deliveryId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound)
+			return response.Error(c, "Webhook delivery not found", nil)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return response.Error(c, "Failed to redeliver webhook", nil)
+	}
+
+	return response.Success(c, "Webhook redelivered", delivery, nil)
+}
@@ -21,26 +21,28 @@ func NewWorkflowHandler(workflowUsecase usecase.WorkflowUsecase) *WorkflowHandle
 
 // CreateWorkflow godoc
 // @Summary Create a new workflow
-// @Description Create a new workflow with a given name
+// @Description Create a new workflow with a given name. submission_mode decides whether new requests are merged into the pending request of the workflow ("merge", default) or always created separately ("separate"). currency is the ISO 4217 code of request amounts and step thresholds (default IDR)
 // @Tags Workflows
 // @Security Bearer
 // @Accept json
 // @Produce json
-// @Param body body object{name=string} true "Create Workflow Request"
+// @Param body body object{name=string,submission_mode=string,currency=string} true "Create Workflow Request"
 // @Success 200 {object} response.ResponseSuccess "Workflow created successfully"
 // @Failure 400 {object} response.ResponseError "Validation error"
 // @Failure 401 {object} response.ResponseError "Unauthorized"
 // @Router /v1/workflows [post]
 func (h *WorkflowHandler) CreateWorkflow(c fiber.Ctx) error {
 	type Body struct {
-		Name string `json:"name" form:"name" query:"name" validate:"required"`
+		Name           string `json:"name" form:"name" query:"name" validate:"required"`
+		SubmissionMode string `json:"submission_mode" form:"submission_mode" query:"submission_mode"`
+		Currency       string `json:"currency" form:"currency" query:"currency"`
 	}
 	body := new(Body)
 	if err := c.Bind().Body(body); err != nil {
 		c.Status(fiber.StatusBadRequest)
 		return response.Error(c, utils.FormatValidationError(err), nil)
 	}
-	w, err := h.workflowUsecase.CreateWorkflow(body.Name)
+	w, err := h.workflowUsecase.CreateWorkflow(body.Name, body.SubmissionMode, body.Currency)
 	if err != nil {
 		return response.Error(c, err.Error(), nil)
 	}
@@ -98,6 +100,7 @@ func (h *WorkflowHandler) FindAllWorkflows(c fiber.Ctx) error {
 // @Failure 400 {object} response.ResponseError "Invalid workflow ID"
 // @Failure 401 {object} response.ResponseError "Unauthorized"
 // @Failure 404 {object} response.ResponseError "Workflow not found"
+// @Header 200 {string} ETag "Current version, to send back in If-Match"
 // @Router /v1/workflows/{workflowId} [get]
 func (h *WorkflowHandler) GetWorkflowByID(c fiber.Ctx) error {
 	workflowId, err := strconv.Atoi(c.Params("workflowId"))
@@ -110,5 +113,6 @@ func (h *WorkflowHandler) GetWorkflowByID(c fiber.Ctx) error {
 	if err != nil {
 		return response.Error(c, "Workflow not found", nil)
 	}
+	setETag(c, workflow.Version)
 	return response.Success(c, "Workflow retrieved successfully", workflow, nil)
 }
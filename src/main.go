@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"strconv"
 	"technical-test/docs"
 	"technical-test/src/config"
 	"technical-test/src/database"
+	"technical-test/src/mailer"
 	"technical-test/src/middleware"
+	"technical-test/src/repository"
 	"technical-test/src/routes"
+	"technical-test/src/usecase"
 	"technical-test/src/utils"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/log"
@@ -43,8 +48,17 @@ func main() {
 	app.Get("/swagger", middleware.SwaggerHandler())
 	app.Get("/swagger.json", middleware.SwaggerHandler())
 
+	// Request events are published to in-process subscribers such as the
+	// request stream
+	bus := usecase.NewEventBus()
+
 	// Setup routes
-	routes.SetupRoutes(app, db)
+	routes.SetupRoutes(app, db, bus)
+
+	// Start background workers
+	ctx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	startWorkers(ctx, db, bus)
 
 	PORT := strconv.Itoa(config.AppPort)
 	if PORT == "" {
@@ -70,6 +84,35 @@ func setupDatabase() *gorm.DB {
 	return db
 }
 
+func startWorkers(ctx context.Context, db *gorm.DB, bus *usecase.EventBus) {
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	sinks := []usecase.OutboxSink{
+		usecase.NewWebhookSink(webhookRepo),
+		usecase.NewLogSink(),
+		bus,
+	}
+	if config.SMTPHost != "" {
+		smtpMailer := mailer.NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+		sinks = append(sinks, usecase.NewNotificationSink(
+			smtpMailer,
+			repository.NewNotificationTemplateRepository(db),
+			repository.NewRequestRepository(db),
+			repository.NewStepRepository(db),
+			repository.NewWorkflowRepository(db),
+			repository.NewApprovalRepository(db),
+			repository.NewUserRepository(db),
+			repository.NewGroupRepository(db),
+		))
+	}
+
+	outboxDispatcher := usecase.NewOutboxDispatcher(repository.NewOutboxRepository(db), sinks...)
+	go outboxDispatcher.Run(ctx, time.Duration(config.OutboxInterval)*time.Second, time.Duration(config.OutboxRetention)*time.Hour)
+
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, repository.NewWorkflowRepository(db))
+	go webhookUsecase.Run(ctx, time.Duration(config.WebhookInterval)*time.Second)
+}
+
 func closeDatabase(db *gorm.DB) {
 	sqlDB, errDB := db.DB()
 	if errDB != nil {
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"technical-test/src/response"
+	"technical-test/src/usecase"
+	"technical-test/src/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// Idempotency replays the stored response, including its ETag, when a call is
+// retried with the same Idempotency-Key header, query, If-Match header and
+// body. Reusing a key for a different call is a 409. Calls without the header
+// are passed through untouched. Responses with a 5xx status are not stored, so
+// those calls can be retried with the same key.
+func Idempotency(idempotencyUsecase usecase.IdempotencyUsecase) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		key := c.Get("Idempotency-Key")
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.Status(fiber.StatusBadRequest)
+			return response.Error(c, "Idempotency-Key must be at most 255 characters", nil)
+		}
+
+		record, replay, err := idempotencyUsecase.Begin(utils.GetUserID(c), key, requestFingerprint(c))
+		if err != nil {
+			if errors.Is(err, usecase.ErrIdempotencyKeyReused) || errors.Is(err, usecase.ErrIdempotencyKeyInProgress) {
+				c.Status(fiber.StatusConflict)
+				return response.Error(c, err.Error(), nil)
+			}
+			c.Status(fiber.StatusInternalServerError)
+			return response.Error(c, "Failed to process idempotency key", nil)
+		}
+
+		if replay {
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if record.ETag != "" {
+				c.Set(fiber.HeaderETag, record.ETag)
+			}
+			return c.Status(record.StatusCode).Send(record.Response)
+		}
+
+		if err := c.Next(); err != nil {
+			idempotencyUsecase.Release(record)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			idempotencyUsecase.Release(record)
+			return nil
+		}
+
+		etag := string(c.Response().Header.Peek(fiber.HeaderETag))
+		if err := idempotencyUsecase.Complete(record, status, etag, c.Response().Body()); err != nil {
+			idempotencyUsecase.Release(record)
+		}
+		return nil
+	}
+}
+
+func requestFingerprint(c fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Path()))
+	hash.Write([]byte{0})
+	hash.Write(c.Request().URI().QueryString())
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Get(fiber.HeaderIfMatch)))
+	hash.Write([]byte{0})
+	hash.Write(c.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
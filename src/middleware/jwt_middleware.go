@@ -29,6 +29,15 @@ func JWTProtected() fiber.Handler {
 		if email, ok := claims["email"]; ok {
 			c.Locals("email", email)
 		}
+		if roles, ok := claims["roles"].([]interface{}); ok {
+			names := make([]string, 0, len(roles))
+			for _, role := range roles {
+				if name, ok := role.(string); ok {
+					names = append(names, name)
+				}
+			}
+			c.Locals("roles", names)
+		}
 
 		return c.Next()
 	}
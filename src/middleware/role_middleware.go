@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"technical-test/src/model"
+	"technical-test/src/response"
+	"technical-test/src/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RequireRoles only lets the request through when the JWT carries at least
+// one of the given roles. Admins are allowed everywhere.
+func RequireRoles(roles ...string) fiber.Handler {
+	allowed := append([]string{model.RoleAdmin}, roles...)
+
+	return func(c fiber.Ctx) error {
+		if utils.HasAnyRole(c, allowed...) {
+			return c.Next()
+		}
+
+		c.Status(fiber.StatusForbidden)
+		return response.Error(c, "You do not have permission to access this resource", nil)
+	}
+}
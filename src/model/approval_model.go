@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+type Approval struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`       // id
+	RequestID  uint      `gorm:"not null;index" json:"request_id"`         // request_id
+	StepLevel  uint      `gorm:"not null" json:"step_level"`               // step_level
+	UserID     uint      `gorm:"not null" json:"user_id"`                  // user_id
+	User       *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`  // user
+	Decision   string    `gorm:"not null" json:"decision"`                 // decision: "APPROVED", "REJECTED", "RETURNED"
+	Comment    string    `gorm:"type:text" json:"comment"`                 // comment
+	Superseded bool      `gorm:"not null;default:false" json:"superseded"` // superseded: set when the request was sent back before this level
+	CreatedAt  time.Time `gorm:"autoCreateTime:milli" json:"created_at"`   // created_at
+}
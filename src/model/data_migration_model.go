@@ -0,0 +1,12 @@
+package model
+
+import (
+	"time"
+)
+
+// DataMigration records a one-off data fix that already ran, so it is not
+// applied again on the next startup.
+type DataMigration struct {
+	Name      string    `gorm:"primaryKey;size:100" json:"name"`        // name
+	AppliedAt time.Time `gorm:"autoCreateTime:milli" json:"applied_at"` // applied_at
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExchangeRate converts amounts between currencies: one unit of FromCurrency is
+// worth Rate units of ToCurrency from EffectiveDate until a newer rate for the
+// same pair takes over.
+type ExchangeRate struct {
+	ID            uint            `gorm:"primaryKey;autoIncrement" json:"id"`                                               // id
+	FromCurrency  string          `gorm:"size:3;not null;uniqueIndex:idx_exchange_rate_pair_date" json:"from_currency"`     // from_currency
+	ToCurrency    string          `gorm:"size:3;not null;uniqueIndex:idx_exchange_rate_pair_date" json:"to_currency"`       // to_currency
+	Rate          decimal.Decimal `gorm:"type:decimal(20,8);not null" json:"rate"`                                          // rate
+	EffectiveDate time.Time       `gorm:"type:date;not null;uniqueIndex:idx_exchange_rate_pair_date" json:"effective_date"` // effective_date
+	CreatedAt     time.Time       `gorm:"autoCreateTime:milli" json:"created_at"`                                           // created_at
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+type Group struct {
+	ID        uint          `gorm:"primaryKey;autoIncrement" json:"id"`          // id
+	Name      string        `gorm:"not null;unique" json:"name"`                 // name
+	Members   []GroupMember `gorm:"foreignKey:GroupID" json:"members,omitempty"` // members
+	CreatedAt time.Time     `gorm:"autoCreateTime:milli" json:"created_at"`      // created_at
+}
+
+// TableName avoids GROUPS, which is a reserved word in MySQL 8.
+func (Group) TableName() string {
+	return "user_groups"
+}
+
+type GroupMember struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`                    // id
+	GroupID   uint      `gorm:"not null;uniqueIndex:idx_group_member" json:"group_id"` // group_id
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_group_member" json:"user_id"`  // user_id
+	User      *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`               // user
+	CreatedAt time.Time `gorm:"autoCreateTime:milli" json:"created_at"`                // created_at
+}
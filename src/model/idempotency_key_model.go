@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// IdempotencyKey stores the outcome of a mutating call made with an
+// Idempotency-Key header, so retries get the original response. A record with
+// StatusCode 0 is still being processed.
+type IdempotencyKey struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`                                // id
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_idempotency_user_key" json:"user_id"`      // user_id
+	Key         string    `gorm:"not null;size:255;uniqueIndex:idx_idempotency_user_key" json:"key"` // key
+	Fingerprint string    `gorm:"not null;size:64" json:"fingerprint"`                               // fingerprint: sha256 of method, path, query, If-Match and body
+	StatusCode  int       `gorm:"not null;default:0" json:"status_code"`                             // status_code
+	ETag        string    `gorm:"size:255" json:"etag"`                                              // etag of the response
+	Response    []byte    `json:"-"`                                                                 // response body
+	CreatedAt   time.Time `gorm:"autoCreateTime:milli" json:"created_at"`                            // created_at
+}
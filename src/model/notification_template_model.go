@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationTemplate overrides the wording of one kind of notification email
+// for one workflow, or for every workflow without an override of its own when
+// WorkflowID is nil. Subject and TextBody are text/template sources, HTMLBody an
+// optional html/template source sent as the HTML alternative.
+type NotificationTemplate struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`                                       // id
+	WorkflowID *uint     `gorm:"uniqueIndex:idx_notification_template_scope" json:"workflow_id"`           // workflow_id: nil for the global default
+	Kind       string    `gorm:"not null;size:50;uniqueIndex:idx_notification_template_scope" json:"kind"` // kind: "STEP_ASSIGNED", "REQUEST_APPROVED", "REQUEST_REJECTED", "REQUEST_RETURNED"
+	Subject    string    `gorm:"not null;size:1000" json:"subject"`                                        // subject
+	TextBody   string    `gorm:"type:text;not null" json:"text_body"`                                      // text_body
+	HTMLBody   string    `gorm:"type:text" json:"html_body"`                                               // html_body: empty to send plain text only
+	Version    uint      `gorm:"not null;default:1" json:"version"`                                        // version: bumped on every update, exposed as the ETag
+	CreatedBy  uint      `gorm:"not null" json:"created_by"`                                               // created_by
+	CreatedAt  time.Time `gorm:"autoCreateTime:milli" json:"created_at"`                                   // created_at
+	UpdatedAt  time.Time `gorm:"autoUpdateTime:milli" json:"updated_at"`                                   // updated_at
+}
+
+func (t *NotificationTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.Version == 0 {
+		t.Version = 1
+	}
+	return nil
+}
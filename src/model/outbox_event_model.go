@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// OutboxEvent is a domain event written in the transaction of the change it
+// describes, and published to the outbox sinks after that transaction
+// committed. DeliveredAt is set once every sink accepted it.
+type OutboxEvent struct {
+	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`                                      // id
+	RequestID     uint           `gorm:"not null;index" json:"request_id"`                                        // request_id
+	WorkflowID    uint           `gorm:"not null" json:"workflow_id"`                                             // workflow_id
+	Type          string         `gorm:"not null;size:50" json:"type"`                                            // type: request event type
+	Payload       datatypes.JSON `gorm:"type:json;not null" json:"payload"`                                       // payload
+	Attempts      uint           `gorm:"not null;default:0" json:"attempts"`                                      // attempts
+	PublishedTo   string         `gorm:"not null;size:255;default:''" json:"published_to"`                        // published_to: comma separated sinks that accepted the event
+	LastError     string         `gorm:"size:1000" json:"last_error"`                                             // last_error
+	NextAttemptAt time.Time      `gorm:"not null;index:idx_outbox_pending" json:"next_attempt_at"`                // next_attempt_at
+	DeliveredAt   *time.Time     `gorm:"index:idx_outbox_pending;index:idx_outbox_delivered" json:"delivered_at"` // delivered_at
+	CreatedAt     time.Time      `gorm:"autoCreateTime:milli" json:"created_at"`                                  // created_at
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// RefreshToken is one refresh token issued to a user. Only the SHA-256 of the
+// token is stored. Every refresh replaces the token with a new one of the same
+// family, which starts at login; a rotated token presented again means it
+// leaked, and the whole family is revoked.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`      // id
+	UserID    uint       `gorm:"not null;index" json:"user_id"`           // user_id
+	FamilyID  string     `gorm:"not null;size:64;index" json:"family_id"` // family_id: shared by the tokens rotated from one login
+	TokenHash string     `gorm:"not null;size:64;uniqueIndex" json:"-"`   // token_hash: hex sha256 of the token
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`              // expires_at
+	RotatedAt *time.Time `json:"rotated_at"`                              // rotated_at: set once the token was exchanged for a new one
+	RevokedAt *time.Time `json:"revoked_at"`                              // revoked_at: set when the family is revoked
+	CreatedAt time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`  // created_at
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	RequestEventCreated      = "CREATED"
+	RequestEventAmountMerged = "AMOUNT_MERGED"
+	RequestEventStepAdvanced = "STEP_ADVANCED"
+	RequestEventApproved     = "APPROVED"
+	RequestEventRejected     = "REJECTED"
+	RequestEventCancelled    = "CANCELLED"
+	RequestEventReturned     = "RETURNED"
+	RequestEventResubmitted  = "RESUBMITTED"
+)
+
+// RequestEvent is an append-only entry in the history of a request. Rows are
+// only ever inserted, never updated.
+type RequestEvent struct {
+	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`        // id
+	RequestID uint           `gorm:"not null;index" json:"request_id"`          // request_id
+	Type      string         `gorm:"not null;size:50" json:"type"`              // type: "CREATED", "AMOUNT_MERGED", "STEP_ADVANCED", "APPROVED", "REJECTED", "CANCELLED", "RETURNED", "RESUBMITTED"
+	ActorID   *uint          `json:"actor_id"`                                  // actor_id
+	Actor     *User          `gorm:"foreignKey:ActorID" json:"actor,omitempty"` // actor
+	Before    datatypes.JSON `gorm:"type:json" json:"before"`                   // before
+	After     datatypes.JSON `gorm:"type:json" json:"after"`                    // after
+	CreatedAt time.Time      `gorm:"autoCreateTime:milli" json:"created_at"`    // created_at
+}
@@ -2,13 +2,47 @@ package model
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type Request struct {
-	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`     // id
-	WorkflowID  uint      `gorm:"not null" json:"workflow_id"`            // workflow_id
-	CurrentStep uint      `gorm:"not null" json:"current_step"`           // current_step
-	Status      string    `gorm:"not null" json:"status"`                 // status: "pending", "approved", "rejected"
-	Amount      float64   `gorm:"not null" json:"amount"`                 // amount
-	CreatedAt   time.Time `gorm:"autoCreateTime:milli" json:"created_at"` // created_at
+	ID                uint            `gorm:"primaryKey;autoIncrement" json:"id"`                         // id
+	WorkflowID        uint            `gorm:"not null" json:"workflow_id"`                                // workflow_id
+	RequesterID       uint            `gorm:"index" json:"requester_id"`                                  // requester_id
+	CurrentStep       uint            `gorm:"not null" json:"current_step"`                               // current_step
+	Status            string          `gorm:"not null" json:"status"`                                     // status: "pending", "approved", "rejected", "cancelled", "returned"
+	Amount            decimal.Decimal `gorm:"type:decimal(20,4);not null" json:"amount"`                  // amount
+	Currency          string          `gorm:"size:3;not null;default:IDR" json:"currency"`                // currency: ISO 4217 code of amount
+	BaseAmount        decimal.Decimal `gorm:"type:decimal(20,4);not null;default:0" json:"base_amount"`   // base_amount: amount converted to the workflow currency, compared with step thresholds
+	BaseCurrency      string          `gorm:"size:3;not null;default:''" json:"base_currency"`            // base_currency: workflow currency at submission
+	ExchangeRate      decimal.Decimal `gorm:"type:decimal(20,8);not null;default:1" json:"exchange_rate"` // exchange_rate: currency->base_currency rate, amount x exchange_rate = base_amount
+	RejectionReason   string          `gorm:"type:text" json:"rejection_reason"`                          // rejection_reason
+	ResumeLevel       uint            `gorm:"not null;default:0" json:"resume_level"`                     // resume_level: level a RETURNED request restarts from on resubmit
+	SubmissionMode    string          `gorm:"size:20" json:"submission_mode"`                             // submission_mode: mode of the workflow applied when the request was created
+	Metadata          datatypes.JSON  `gorm:"type:json" json:"metadata"`                                  // metadata
+	PendingWorkflowID *uint           `gorm:"uniqueIndex" json:"-"`                                       // pending_workflow_id: set only while the request is the PENDING merge target of its workflow
+	Version           uint            `gorm:"not null;default:1" json:"version"`                          // version: bumped on every update, exposed as the ETag
+	CreatedAt         time.Time       `gorm:"autoCreateTime:milli" json:"created_at"`                     // created_at
+}
+
+// BeforeSave releases the pending slot of the workflow once the request leaves
+// PENDING, so the unique index on pending_workflow_id only covers live merge
+// targets.
+func (r *Request) BeforeSave(tx *gorm.DB) error {
+	if r.Status != "PENDING" {
+		r.PendingWorkflowID = nil
+	}
+	return nil
+}
+
+// BeforeCreate starts new requests at version 1 so the first If-Match a
+// client sends matches the ETag returned on create.
+func (r *Request) BeforeCreate(tx *gorm.DB) error {
+	if r.Version == 0 {
+		r.Version = 1
+	}
+	return nil
 }
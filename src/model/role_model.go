@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// Role is a custom role created by an admin, such as "Manager" or "CFO", that
+// can be assigned to users and used as a step actor. The built-in roles in
+// Roles are always available and are not stored here.
+type Role struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`     // id
+	Name      string    `gorm:"not null;size:100;unique" json:"name"`   // name
+	CreatedBy uint      `gorm:"not null" json:"created_by"`             // created_by
+	CreatedAt time.Time `gorm:"autoCreateTime:milli" json:"created_at"` // created_at
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type Step struct {
@@ -12,5 +13,13 @@ type Step struct {
 	Level      uint           `gorm:"not null" json:"level"`                  // level
 	Actor      string         `gorm:"not null" json:"actor"`                  // actor
 	Conditions datatypes.JSON `gorm:"type:json" json:"conditions"`            // conditions
+	Version    uint           `gorm:"not null;default:1" json:"version"`      // version: bumped on every update, exposed as the ETag
 	CreatedAt  time.Time      `gorm:"autoCreateTime:milli" json:"created_at"` // created_at
 }
+
+func (s *Step) BeforeCreate(tx *gorm.DB) error {
+	if s.Version == 0 {
+		s.Version = 1
+	}
+	return nil
+}
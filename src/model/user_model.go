@@ -3,9 +3,19 @@ package model
 import "time"
 
 type User struct {
-	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`     // id
-	Name         string    `gorm:"not null" json:"name"`                   // name
-	Email        string    `gorm:"not null;unique" json:"email"`           // email
-	PasswordHash string    `gorm:"not null" json:"-"`                      // password_hash
-	CreatedAt    time.Time `gorm:"autoCreateTime:milli" json:"created_at"` // created_at
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`     // id
+	Name         string     `gorm:"not null" json:"name"`                   // name
+	Email        string     `gorm:"not null;unique" json:"email"`           // email
+	PasswordHash string     `gorm:"not null" json:"-"`                      // password_hash
+	Roles        []UserRole `gorm:"foreignKey:UserID" json:"-"`             // roles
+	CreatedAt    time.Time  `gorm:"autoCreateTime:milli" json:"created_at"` // created_at
+}
+
+// RoleNames returns the names of the preloaded roles of the user.
+func (u User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Role)
+	}
+	return names
 }
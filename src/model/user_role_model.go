@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	RoleAdmin            = "admin"
+	RoleWorkflowDesigner = "workflow-designer"
+	RoleRequester        = "requester"
+	RoleApprover         = "approver"
+)
+
+// Roles lists every role that can be assigned to a user.
+var Roles = []string{RoleAdmin, RoleWorkflowDesigner, RoleRequester, RoleApprover}
+
+type UserRole struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`                      // id
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_role" json:"user_id"`       // user_id
+	Role      string    `gorm:"not null;size:100;uniqueIndex:idx_user_role" json:"role"` // role
+	CreatedAt time.Time `gorm:"autoCreateTime:milli" json:"created_at"`                  // created_at
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+// WebhookSubscription receives request events of one workflow, or of every
+// workflow when WorkflowID is nil, as signed HTTP POST calls to URL.
+type WebhookSubscription struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`     // id
+	WorkflowID *uint     `gorm:"index" json:"workflow_id"`               // workflow_id: nil for every workflow
+	URL        string    `gorm:"not null;size:2048" json:"url"`          // url
+	Secret     string    `gorm:"not null;size:255" json:"-"`             // secret: HMAC-SHA256 signing key
+	Events     string    `gorm:"not null;size:255" json:"events"`        // events: comma separated request event types
+	CreatedBy  uint      `gorm:"not null" json:"created_by"`             // created_by
+	CreatedAt  time.Time `gorm:"autoCreateTime:milli" json:"created_at"` // created_at
+	UpdatedAt  time.Time `gorm:"autoUpdateTime:milli" json:"updated_at"` // updated_at
+}
+
+// WebhookDelivery is one event queued for one subscription, with the outcome of
+// its latest attempt. Pending deliveries are sent again at NextAttemptAt.
+type WebhookDelivery struct {
+	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`                            // id
+	SubscriptionID uint           `gorm:"not null;index" json:"subscription_id"`                         // subscription_id
+	RequestID      uint           `gorm:"not null;index" json:"request_id"`                              // request_id
+	EventType      string         `gorm:"not null;size:50" json:"event_type"`                            // event_type
+	Payload        datatypes.JSON `gorm:"type:json;not null" json:"payload"`                             // payload
+	Status         string         `gorm:"not null;size:20;index:idx_webhook_delivery_due" json:"status"` // status: "PENDING", "DELIVERED", "FAILED"
+	Attempts       uint           `gorm:"not null;default:0" json:"attempts"`                            // attempts
+	NextAttemptAt  *time.Time     `gorm:"index:idx_webhook_delivery_due" json:"next_attempt_at"`         // next_attempt_at
+	ResponseStatus int            `gorm:"not null;default:0" json:"response_status"`                     // response_status of the latest attempt
+	LastError      string         `gorm:"size:1000" json:"last_error"`                                   // last_error
+	DeliveredAt    *time.Time     `json:"delivered_at"`                                                  // delivered_at
+	CreatedAt      time.Time      `gorm:"autoCreateTime:milli" json:"created_at"`                        // created_at
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime:milli" json:"updated_at"`                        // updated_at
+}
@@ -2,10 +2,31 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Submission modes decide what CreateRequest does with a new amount:
+//
+//	merge     add it onto the PENDING request of the workflow (default)
+//	separate  always create a new request, e.g. for expense claims
+const (
+	SubmissionModeMerge    = "merge"
+	SubmissionModeSeparate = "separate"
 )
 
 type Workflow struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`     // id
-	Name      string    `gorm:"not null;unique" json:"name"`            // name
-	CreatedAt time.Time `gorm:"autoCreateTime:milli" json:"created_at"` // created_at
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`                    // id
+	Name           string    `gorm:"not null;unique" json:"name"`                           // name
+	SubmissionMode string    `gorm:"not null;size:20;default:merge" json:"submission_mode"` // submission_mode: "merge", "separate"
+	Currency       string    `gorm:"not null;size:3;default:IDR" json:"currency"`           // currency: ISO 4217 code of request amounts and step thresholds
+	Version        uint      `gorm:"not null;default:1" json:"version"`                     // version: bumped whenever the workflow or its steps change
+	CreatedAt      time.Time `gorm:"autoCreateTime:milli" json:"created_at"`                // created_at
+}
+
+func (w *Workflow) BeforeCreate(tx *gorm.DB) error {
+	if w.Version == 0 {
+		w.Version = 1
+	}
+	return nil
 }
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+type ApprovalRepository interface {
+	CreateTx(tx *gorm.DB, approval *model.Approval) error
+	FindByRequestID(requestID int) ([]model.Approval, error)
+	ExistsTx(tx *gorm.DB, requestID, stepLevel, userID uint) (bool, error)
+	ExistsForUserTx(tx *gorm.DB, requestID, userID uint) (bool, error)
+	FindDeciderIDsTx(tx *gorm.DB, requestID uint) ([]uint, error)
+	FindApproverIDsTx(tx *gorm.DB, requestID, stepLevel uint) ([]uint, error)
+	SupersedeFromLevelTx(tx *gorm.DB, requestID, fromLevel uint) error
+}
+
+type approvalRepository struct {
+	db *gorm.DB
+}
+
+func NewApprovalRepository(db *gorm.DB) ApprovalRepository {
+	return &approvalRepository{db: db}
+}
+
+func (r *approvalRepository) CreateTx(tx *gorm.DB, approval *model.Approval) error {
+	return tx.Create(approval).Error
+}
+
+func (r *approvalRepository) FindByRequestID(requestID int) ([]model.Approval, error) {
+	var approvals []model.Approval
+	err := r.db.Preload("User").
+		Where("request_id = ?", requestID).
+		Order("created_at ASC, id ASC").
+		Find(&approvals).Error
+	return approvals, err
+}
+
+func (r *approvalRepository) ExistsTx(tx *gorm.DB, requestID, stepLevel, userID uint) (bool, error) {
+	var total int64
+	err := tx.Model(&model.Approval{}).
+		Where("request_id = ? AND step_level = ? AND user_id = ? AND superseded = ?", requestID, stepLevel, userID, false).
+		Count(&total).Error
+	return total > 0, err
+}
+
+func (r *approvalRepository) ExistsForUserTx(tx *gorm.DB, requestID, userID uint) (bool, error) {
+	var total int64
+	err := tx.Model(&model.Approval{}).
+		Where("request_id = ? AND user_id = ?", requestID, userID).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// FindDeciderIDsTx lists the users who recorded any decision on the request.
+func (r *approvalRepository) FindDeciderIDsTx(tx *gorm.DB, requestID uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&model.Approval{}).
+		Distinct("user_id").
+		Where("request_id = ?", requestID).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *approvalRepository) FindApproverIDsTx(tx *gorm.DB, requestID, stepLevel uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&model.Approval{}).
+		Distinct("user_id").
+		Where("request_id = ? AND step_level = ? AND decision = ? AND superseded = ?", requestID, stepLevel, "APPROVED", false).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// SupersedeFromLevelTx marks the decisions recorded at fromLevel and later as
+// superseded, so they no longer count once the request goes through those
+// levels again.
+func (r *approvalRepository) SupersedeFromLevelTx(tx *gorm.DB, requestID, fromLevel uint) error {
+	return tx.Model(&model.Approval{}).
+		Where("request_id = ? AND step_level >= ?", requestID, fromLevel).
+		Update("superseded", true).Error
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"technical-test/src/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRepository interface {
+	Create(rate *model.ExchangeRate) error
+	UpsertTx(tx *gorm.DB, rate *model.ExchangeRate) error
+	FindByID(id int) (model.ExchangeRate, error)
+	FindAllWithPagination(offset, limit int, fromCurrency, toCurrency string) ([]model.ExchangeRate, int64, error)
+	FindEffectiveTx(tx *gorm.DB, fromCurrency, toCurrency string, date time.Time) (model.ExchangeRate, error)
+	Delete(id int) error
+	BeginTransaction() *gorm.DB
+}
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) Create(rate *model.ExchangeRate) error {
+	return r.db.Create(rate).Error
+}
+
+// UpsertTx creates the rate or replaces the rate already stored for the same
+// currency pair and effective date.
+func (r *exchangeRateRepository) UpsertTx(tx *gorm.DB, rate *model.ExchangeRate) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "from_currency"}, {Name: "to_currency"}, {Name: "effective_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate"}),
+	}).Create(rate).Error
+}
+
+func (r *exchangeRateRepository) FindByID(id int) (model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := r.db.First(&rate, id).Error
+	return rate, err
+}
+
+func (r *exchangeRateRepository) FindAllWithPagination(offset, limit int, fromCurrency, toCurrency string) ([]model.ExchangeRate, int64, error) {
+	var rates []model.ExchangeRate
+	var total int64
+
+	query := r.db.Model(&model.ExchangeRate{})
+	if fromCurrency != "" {
+		query = query.Where("from_currency = ?", fromCurrency)
+	}
+	if toCurrency != "" {
+		query = query.Where("to_currency = ?", toCurrency)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return rates, 0, err
+	}
+
+	err := query.
+		Order("effective_date DESC, from_currency ASC, to_currency ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&rates).Error
+
+	return rates, total, err
+}
+
+// FindEffectiveTx returns the latest rate for the pair whose effective date is
+// on or before date.
+func (r *exchangeRateRepository) FindEffectiveTx(tx *gorm.DB, fromCurrency, toCurrency string, date time.Time) (model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := tx.Where("from_currency = ? AND to_currency = ? AND effective_date <= ?", fromCurrency, toCurrency, date).
+		Order("effective_date DESC").
+		First(&rate).Error
+	return rate, err
+}
+
+func (r *exchangeRateRepository) Delete(id int) error {
+	return r.db.Delete(&model.ExchangeRate{}, id).Error
+}
+
+func (r *exchangeRateRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
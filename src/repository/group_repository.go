@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+type GroupRepository interface {
+	Create(group *model.Group) error
+	FindByID(id int) (model.Group, error)
+	FindByName(name string) (model.Group, error)
+	FindAllWithPagination(offset, limit int, search string) ([]model.Group, int64, error)
+	AddMember(member *model.GroupMember) error
+	RemoveMember(groupID, userID uint) error
+	FindNamesByUserIDTx(tx *gorm.DB, userID uint) ([]string, error)
+	FindMemberIDsByNameTx(tx *gorm.DB, name string) ([]uint, error)
+}
+
+type groupRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepository{db: db}
+}
+
+func (r *groupRepository) Create(group *model.Group) error {
+	return r.db.Create(group).Error
+}
+
+func (r *groupRepository) FindByID(id int) (model.Group, error) {
+	var group model.Group
+	err := r.db.Preload("Members.User").First(&group, id).Error
+	return group, err
+}
+
+func (r *groupRepository) FindByName(name string) (model.Group, error) {
+	var group model.Group
+	err := r.db.Where("name = ?", name).First(&group).Error
+	return group, err
+}
+
+func (r *groupRepository) FindAllWithPagination(offset, limit int, search string) ([]model.Group, int64, error) {
+	var groups []model.Group
+	var total int64
+
+	query := r.db.Model(&model.Group{})
+	if search != "" {
+		query = query.Where("name LIKE ?", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return groups, 0, err
+	}
+
+	err := query.
+		Order("name ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&groups).Error
+
+	return groups, total, err
+}
+
+func (r *groupRepository) AddMember(member *model.GroupMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *groupRepository) RemoveMember(groupID, userID uint) error {
+	result := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&model.GroupMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *groupRepository) FindNamesByUserIDTx(tx *gorm.DB, userID uint) ([]string, error) {
+	var names []string
+	err := tx.Model(&model.Group{}).
+		Joins("JOIN group_members ON group_members.group_id = user_groups.id").
+		Where("group_members.user_id = ?", userID).
+		Order("user_groups.name ASC").
+		Pluck("user_groups.name", &names).Error
+	return names, err
+}
+
+func (r *groupRepository) FindMemberIDsByNameTx(tx *gorm.DB, name string) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&model.GroupMember{}).
+		Joins("JOIN user_groups ON user_groups.id = group_members.group_id").
+		Where("LOWER(user_groups.name) = LOWER(?)", name).
+		Order("group_members.user_id ASC").
+		Pluck("group_members.user_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyRepository interface {
+	Create(record *model.IdempotencyKey) error
+	FindByUserAndKey(userID uint, key string) (model.IdempotencyKey, error)
+	Update(record *model.IdempotencyKey) error
+	Delete(id uint) error
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Create(record *model.IdempotencyKey) error {
+	return r.db.Create(record).Error
+}
+
+func (r *idempotencyRepository) FindByUserAndKey(userID uint, key string) (model.IdempotencyKey, error) {
+	var record model.IdempotencyKey
+	err := r.db.Where("user_id = ? AND `key` = ?", userID, key).First(&record).Error
+	return record, err
+}
+
+func (r *idempotencyRepository) Update(record *model.IdempotencyKey) error {
+	return r.db.Save(record).Error
+}
+
+func (r *idempotencyRepository) Delete(id uint) error {
+	return r.db.Delete(&model.IdempotencyKey{}, id).Error
+}
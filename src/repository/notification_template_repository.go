@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+type NotificationTemplateRepository interface {
+	Create(template *model.NotificationTemplate) error
+	FindByID(id int) (model.NotificationTemplate, error)
+	FindAllWithPagination(offset, limit int, kind string, workflowID int) ([]model.NotificationTemplate, int64, error)
+	FindByScope(kind string, workflowID *uint) (model.NotificationTemplate, error)
+	FindEffective(kind string, workflowID uint) (model.NotificationTemplate, error)
+	Update(template *model.NotificationTemplate) error
+	Delete(id int, version uint) error
+}
+
+type notificationTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationTemplateRepository(db *gorm.DB) NotificationTemplateRepository {
+	return &notificationTemplateRepository{db: db}
+}
+
+func (r *notificationTemplateRepository) Create(template *model.NotificationTemplate) error {
+	return r.db.Create(template).Error
+}
+
+func (r *notificationTemplateRepository) FindByID(id int) (model.NotificationTemplate, error) {
+	var template model.NotificationTemplate
+	err := r.db.First(&template, id).Error
+	return template, err
+}
+
+func (r *notificationTemplateRepository) FindAllWithPagination(offset, limit int, kind string, workflowID int) ([]model.NotificationTemplate, int64, error) {
+	var templates []model.NotificationTemplate
+	var total int64
+
+	query := r.db.Model(&model.NotificationTemplate{})
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if workflowID > 0 {
+		query = query.Where("workflow_id = ?", workflowID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return templates, 0, err
+	}
+
+	err := query.
+		Order("workflow_id ASC, kind ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&templates).Error
+
+	return templates, total, err
+}
+
+// FindByScope returns the template of the kind stored for exactly this
+// workflow, or the global default when workflowID is nil.
+func (r *notificationTemplateRepository) FindByScope(kind string, workflowID *uint) (model.NotificationTemplate, error) {
+	var template model.NotificationTemplate
+	query := r.db.Where("kind = ?", kind)
+	if workflowID == nil {
+		query = query.Where("workflow_id IS NULL")
+	} else {
+		query = query.Where("workflow_id = ?", *workflowID)
+	}
+	err := query.First(&template).Error
+	return template, err
+}
+
+// FindEffective returns the template of the kind used for the workflow: its
+// own override when there is one, otherwise the global default.
+func (r *notificationTemplateRepository) FindEffective(kind string, workflowID uint) (model.NotificationTemplate, error) {
+	var template model.NotificationTemplate
+	err := r.db.Where("kind = ? AND (workflow_id IS NULL OR workflow_id = ?)", kind, workflowID).
+		Order("workflow_id IS NULL ASC").
+		First(&template).Error
+	return template, err
+}
+
+func (r *notificationTemplateRepository) Update(template *model.NotificationTemplate) error {
+	return updateVersioned(r.db, template, &template.Version)
+}
+
+// Delete removes the template if it is still at version, or at any version
+// when version is 0.
+func (r *notificationTemplateRepository) Delete(id int, version uint) error {
+	query := r.db.Where("id = ?", id)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	result := query.Delete(&model.NotificationTemplate{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		if version == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return ErrStaleVersion
+	}
+	return result.Error
+}
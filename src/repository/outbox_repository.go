@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"technical-test/src/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	CreateTx(tx *gorm.DB, event *model.OutboxEvent) error
+	FindPending(now time.Time, limit int) ([]model.OutboxEvent, error)
+	Update(event *model.OutboxEvent) error
+	DeleteDeliveredBefore(before time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) CreateTx(tx *gorm.DB, event *model.OutboxEvent) error {
+	return tx.Create(event).Error
+}
+
+// FindPending returns undelivered events whose next attempt is due, in the
+// order they were written.
+func (r *outboxRepository) FindPending(now time.Time, limit int) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+	err := r.db.Where("delivered_at IS NULL AND next_attempt_at <= ?", now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *outboxRepository) Update(event *model.OutboxEvent) error {
+	return r.db.Save(event).Error
+}
+
+// DeleteDeliveredBefore removes events every sink accepted before the given
+// time and returns how many were removed. Undelivered events are kept.
+func (r *outboxRepository) DeleteDeliveredBefore(before time.Time) (int64, error) {
+	result := r.db.Where("delivered_at IS NOT NULL AND delivered_at < ?", before).Delete(&model.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"technical-test/src/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *model.RefreshToken) error
+	CreateTx(tx *gorm.DB, token *model.RefreshToken) error
+	FindByHashWithLock(tx *gorm.DB, tokenHash string) (model.RefreshToken, error)
+	UpdateTx(tx *gorm.DB, token *model.RefreshToken) error
+	RevokeFamilyTx(tx *gorm.DB, familyID string, now time.Time) error
+	BeginTransaction() *gorm.DB
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(token *model.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *refreshTokenRepository) CreateTx(tx *gorm.DB, token *model.RefreshToken) error {
+	return tx.Create(token).Error
+}
+
+// FindByHashWithLock locks the token so two refreshes with the same token
+// cannot both rotate it.
+func (r *refreshTokenRepository) FindByHashWithLock(tx *gorm.DB, tokenHash string) (model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	return token, err
+}
+
+func (r *refreshTokenRepository) UpdateTx(tx *gorm.DB, token *model.RefreshToken) error {
+	return tx.Save(token).Error
+}
+
+// RevokeFamilyTx revokes every token of the family not revoked yet.
+func (r *refreshTokenRepository) RevokeFamilyTx(tx *gorm.DB, familyID string, now time.Time) error {
+	return tx.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+func (r *refreshTokenRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
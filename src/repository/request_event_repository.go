@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+type RequestEventRepository interface {
+	CreateTx(tx *gorm.DB, event *model.RequestEvent) error
+	FindByRequestID(requestID int) ([]model.RequestEvent, error)
+	FindAfterID(afterID uint, workflowID uint, limit int) ([]model.RequestEvent, error)
+	LatestID() (uint, error)
+}
+
+type requestEventRepository struct {
+	db *gorm.DB
+}
+
+func NewRequestEventRepository(db *gorm.DB) RequestEventRepository {
+	return &requestEventRepository{db: db}
+}
+
+func (r *requestEventRepository) CreateTx(tx *gorm.DB, event *model.RequestEvent) error {
+	return tx.Create(event).Error
+}
+
+func (r *requestEventRepository) FindByRequestID(requestID int) ([]model.RequestEvent, error) {
+	var events []model.RequestEvent
+	err := r.db.Preload("Actor").
+		Where("request_id = ?", requestID).
+		Order("id ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// FindAfterID returns up to limit events recorded after the event afterID,
+// oldest first, optionally only those of one workflow.
+func (r *requestEventRepository) FindAfterID(afterID uint, workflowID uint, limit int) ([]model.RequestEvent, error) {
+	var events []model.RequestEvent
+
+	query := r.db.Where("id > ?", afterID)
+	if workflowID != 0 {
+		query = query.Where("request_id IN (?)", r.db.Model(&model.Request{}).Select("id").Where("workflow_id = ?", workflowID))
+	}
+
+	err := query.Order("id ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// LatestID returns the ID of the last recorded event, 0 when there is none.
+func (r *requestEventRepository) LatestID() (uint, error) {
+	var id uint
+	err := r.db.Model(&model.RequestEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
@@ -7,12 +7,27 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// RequestFilter narrows the requests returned by FindAllWithPagination.
+// Zero values are ignored.
+type RequestFilter struct {
+	Search      string
+	Status      string
+	RequesterID uint // only requests submitted by this user
+	VisibleTo   uint // only requests this user submitted, decided on, or can act on
+	// VisibleActors are the lower-cased actor strings VisibleTo resolves to;
+	// pending requests whose current step actor is one of them are visible.
+	VisibleActors []string
+}
+
 type RequestRepository interface {
 	Create(request *model.Request) error
+	CreateTx(tx *gorm.DB, request *model.Request) error
 	FindByID(id int) (model.Request, error)
 	FindByIDWithLock(tx *gorm.DB, id int) (model.Request, error)
 	FindPendingByWorkflowID(workflowID int) (model.Request, error)
-	FindAllWithPagination(offset, limit int, search, status string) ([]model.Request, int64, error)
+	FindPendingByWorkflowIDWithLock(tx *gorm.DB, workflowID int) (model.Request, error)
+	FindAllWithPagination(offset, limit int, filter RequestFilter) ([]model.Request, int64, error)
+	FindInboxWithPagination(offset, limit int, userID uint, actors []string) ([]model.Request, int64, error)
 	Update(request *model.Request) error
 	UpdateTx(tx *gorm.DB, request *model.Request) error
 	BeginTransaction() *gorm.DB
@@ -30,6 +45,10 @@ func (r *requestRepository) Create(request *model.Request) error {
 	return r.db.Create(request).Error
 }
 
+func (r *requestRepository) CreateTx(tx *gorm.DB, request *model.Request) error {
+	return tx.Create(request).Error
+}
+
 func (r *requestRepository) FindByID(id int) (model.Request, error) {
 	var request model.Request
 	err := r.db.First(&request, id).Error
@@ -42,22 +61,40 @@ func (r *requestRepository) FindByIDWithLock(tx *gorm.DB, id int) (model.Request
 	return request, err
 }
 
+// FindPendingByWorkflowID returns the request holding the pending slot of the
+// workflow, which is its merge target. Separate requests never hold it.
 func (r *requestRepository) FindPendingByWorkflowID(workflowID int) (model.Request, error) {
 	var request model.Request
-	err := r.db.Where("workflow_id = ? AND status = ?", workflowID, "PENDING").First(&request).Error
+	err := r.db.Where("pending_workflow_id = ?", workflowID).First(&request).Error
+	return request, err
+}
+
+func (r *requestRepository) FindPendingByWorkflowIDWithLock(tx *gorm.DB, workflowID int) (model.Request, error) {
+	var request model.Request
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("pending_workflow_id = ?", workflowID).
+		First(&request).Error
 	return request, err
 }
 
-func (r *requestRepository) FindAllWithPagination(offset, limit int, search, status string) ([]model.Request, int64, error) {
+func (r *requestRepository) FindAllWithPagination(offset, limit int, filter RequestFilter) ([]model.Request, int64, error) {
 	var requests []model.Request
 	var total int64
 
 	query := r.db.Model(&model.Request{})
-	if search != "" {
-		query = query.Where("workflow_id = ?", search)
+	if filter.Search != "" {
+		query = query.Where("workflow_id = ?", filter.Search)
 	}
-	if status != "" {
-		query = query.Where("status = ?", status)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.RequesterID != 0 {
+		query = query.Where("requester_id = ?", filter.RequesterID)
+	}
+	if filter.VisibleTo != 0 {
+		decided := r.db.Model(&model.Approval{}).Select("request_id").Where("user_id = ?", filter.VisibleTo)
+		assigned := r.db.Where("status = ?", "PENDING").Where("EXISTS (?)", currentStepActorIn(r.db, filter.VisibleActors))
+		query = query.Where(r.db.Where("requester_id = ?", filter.VisibleTo).Or("id IN (?)", decided).Or(assigned))
 	}
 
 	if err := query.Count(&total).Error; err != nil {
@@ -73,12 +110,52 @@ func (r *requestRepository) FindAllWithPagination(offset, limit int, search, sta
 	return requests, total, err
 }
 
+// FindInboxWithPagination returns the pending requests whose current step actor
+// is one of the given (lower-cased) actor strings and that the user has not
+// decided on yet at that level, oldest first.
+func (r *requestRepository) FindInboxWithPagination(offset, limit int, userID uint, actors []string) ([]model.Request, int64, error) {
+	var requests []model.Request
+	var total int64
+
+	decided := r.db.Model(&model.Approval{}).
+		Select("1").
+		Where("approvals.request_id = requests.id AND approvals.step_level = requests.current_step AND approvals.user_id = ? AND approvals.superseded = ?", userID, false)
+
+	query := r.db.Model(&model.Request{}).
+		Where("requests.status = ?", "PENDING").
+		Where("EXISTS (?)", currentStepActorIn(r.db, actors)).
+		Where("NOT EXISTS (?)", decided)
+
+	if err := query.Count(&total).Error; err != nil {
+		return requests, 0, err
+	}
+
+	err := query.
+		Select("requests.*").
+		Order("requests.created_at ASC, requests.id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&requests).Error
+
+	return requests, total, err
+}
+
+// currentStepActorIn selects the current step of a request when its actor is
+// one of the given (lower-cased) actor strings. Used by both the inbox and the
+// visibility filter so they agree on who a step is assigned to.
+func currentStepActorIn(db *gorm.DB, actors []string) *gorm.DB {
+	return db.Model(&model.Step{}).
+		Select("1").
+		Where("steps.workflow_id = requests.workflow_id AND steps.level = requests.current_step").
+		Where("LOWER(steps.actor) IN ?", actors)
+}
+
 func (r *requestRepository) Update(request *model.Request) error {
-	return r.db.Save(request).Error
+	return updateVersioned(r.db, request, &request.Version)
 }
 
 func (r *requestRepository) UpdateTx(tx *gorm.DB, request *model.Request) error {
-	return tx.Save(request).Error
+	return updateVersioned(tx, request, &request.Version)
 }
 
 func (r *requestRepository) BeginTransaction() *gorm.DB {
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	Create(role *model.Role) error
+	FindByName(name string) (model.Role, error)
+	FindAllWithPagination(offset, limit int, search string) ([]model.Role, int64, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) Create(role *model.Role) error {
+	return r.db.Create(role).Error
+}
+
+// FindByName matches the role name case-insensitively, the same way role
+// actors are matched against user roles.
+func (r *roleRepository) FindByName(name string) (model.Role, error) {
+	var role model.Role
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&role).Error
+	return role, err
+}
+
+func (r *roleRepository) FindAllWithPagination(offset, limit int, search string) ([]model.Role, int64, error) {
+	var roles []model.Role
+	var total int64
+
+	query := r.db.Model(&model.Role{})
+	if search != "" {
+		query = query.Where("name LIKE ?", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return roles, 0, err
+	}
+
+	err := query.
+		Order("name ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&roles).Error
+
+	return roles, total, err
+}
@@ -8,13 +8,16 @@ import (
 
 type StepRepository interface {
 	Create(step *model.Step) error
+	CreateTx(tx *gorm.DB, step *model.Step) error
 	FindByWorkflowID(workflowID int) ([]model.Step, error)
 	FindByWorkflowIDWithPagination(workflowID int, offset, limit int, search string) ([]model.Step, int64, error)
 	FindByLevelAndWorkflowID(level uint, workflowID int) (model.Step, error)
 	FindByLevelAndWorkflowIDTx(tx *gorm.DB, level uint, workflowID int) (model.Step, error)
 	FindByID(id int) (model.Step, error)
+	FindByIDTx(tx *gorm.DB, id int) (model.Step, error)
 	GetMaxLevel(workflowID int) (uint, error)
-	Update(step *model.Step) error
+	GetMaxLevelTx(tx *gorm.DB, workflowID int) (uint, error)
+	UpdateTx(tx *gorm.DB, step *model.Step) error
 	Delete(id int) error
 }
 
@@ -30,6 +33,10 @@ func (r *stepRepository) Create(step *model.Step) error {
 	return r.db.Create(step).Error
 }
 
+func (r *stepRepository) CreateTx(tx *gorm.DB, step *model.Step) error {
+	return tx.Create(step).Error
+}
+
 func (r *stepRepository) FindByWorkflowID(workflowID int) ([]model.Step, error) {
 	var steps []model.Step
 	err := r.db.Where("workflow_id = ?", workflowID).Find(&steps).Error
@@ -76,6 +83,12 @@ func (r *stepRepository) FindByID(id int) (model.Step, error) {
 	return step, err
 }
 
+func (r *stepRepository) FindByIDTx(tx *gorm.DB, id int) (model.Step, error) {
+	var step model.Step
+	err := tx.First(&step, id).Error
+	return step, err
+}
+
 func (r *stepRepository) GetMaxLevel(workflowID int) (uint, error) {
 	var maxLevel uint
 	r.db.Model(&model.Step{}).
@@ -86,8 +99,18 @@ func (r *stepRepository) GetMaxLevel(workflowID int) (uint, error) {
 	return maxLevel, nil
 }
 
-func (r *stepRepository) Update(step *model.Step) error {
-	return r.db.Save(step).Error
+func (r *stepRepository) GetMaxLevelTx(tx *gorm.DB, workflowID int) (uint, error) {
+	var maxLevel uint
+	err := tx.Model(&model.Step{}).
+		Where("workflow_id = ?", workflowID).
+		Select("COALESCE(MAX(level), 0)").
+		Row().
+		Scan(&maxLevel)
+	return maxLevel, err
+}
+
+func (r *stepRepository) UpdateTx(tx *gorm.DB, step *model.Step) error {
+	return updateVersioned(tx, step, &step.Version)
 }
 
 func (r *stepRepository) Delete(id int) error {
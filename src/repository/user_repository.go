@@ -4,11 +4,20 @@ import (
 	"technical-test/src/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	FindByEmail(email string) (model.User, error)
-	Create(user *model.User) error
+	FindByID(id int) (model.User, error)
+	FindByIDTx(tx *gorm.DB, id int) (model.User, error)
+	FindAllWithPagination(offset, limit int, search string) ([]model.User, int64, error)
+	CountWithLock(tx *gorm.DB) (int64, error)
+	CreateTx(tx *gorm.DB, user *model.User) error
+	ReplaceRoles(userID uint, roles []string) error
+	FindRolesTx(tx *gorm.DB, userID uint) ([]string, error)
+	FindIDsByRoleTx(tx *gorm.DB, role string) ([]uint, error)
+	BeginTransaction() *gorm.DB
 }
 
 type userRepository struct {
@@ -21,10 +30,92 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 
 func (r *userRepository) FindByEmail(email string) (model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.Preload("Roles").Where("email = ?", email).First(&user).Error
 	return user, err
 }
 
-func (r *userRepository) Create(user *model.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) FindByID(id int) (model.User, error) {
+	var user model.User
+	err := r.db.Preload("Roles").First(&user, id).Error
+	return user, err
+}
+
+func (r *userRepository) FindByIDTx(tx *gorm.DB, id int) (model.User, error) {
+	var user model.User
+	err := tx.Preload("Roles").First(&user, id).Error
+	return user, err
+}
+
+func (r *userRepository) FindAllWithPagination(offset, limit int, search string) ([]model.User, int64, error) {
+	var users []model.User
+	var total int64
+
+	query := r.db.Model(&model.User{})
+	if search != "" {
+		query = query.Where("name LIKE ? OR email LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return users, 0, err
+	}
+
+	err := query.Preload("Roles").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&users).Error
+
+	return users, total, err
+}
+
+// CountWithLock counts the users while locking the scanned rows, and on MySQL
+// the gap after them, so concurrent registrations cannot all see an empty
+// table.
+func (r *userRepository) CountWithLock(tx *gorm.DB) (int64, error) {
+	var total int64
+	err := tx.Model(&model.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Count(&total).Error
+	return total, err
+}
+
+func (r *userRepository) CreateTx(tx *gorm.DB, user *model.User) error {
+	return tx.Create(user).Error
+}
+
+func (r *userRepository) ReplaceRoles(userID uint, roles []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+
+		for _, role := range roles {
+			if err := tx.Create(&model.UserRole{UserID: userID, Role: role}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) FindRolesTx(tx *gorm.DB, userID uint) ([]string, error) {
+	var roles []string
+	err := tx.Model(&model.UserRole{}).
+		Where("user_id = ?", userID).
+		Order("role ASC").
+		Pluck("role", &roles).Error
+	return roles, err
+}
+
+func (r *userRepository) FindIDsByRoleTx(tx *gorm.DB, role string) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&model.UserRole{}).
+		Where("LOWER(role) = LOWER(?)", role).
+		Order("user_id ASC").
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *userRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrStaleVersion is returned when a versioned row was changed by someone else
+// between reading and writing it.
+var ErrStaleVersion = errors.New("record was modified by another request")
+
+// updateVersioned writes every column of value only if the stored version is
+// still the one that was read, then bumps the version. It replaces Save, which
+// would silently overwrite a concurrent change.
+func updateVersioned(tx *gorm.DB, value interface{}, version *uint) error {
+	current := *version
+	*version = current + 1
+
+	result := tx.Model(value).Where("version = ?", current).Select("*").Updates(value)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrStaleVersion
+	}
+	if result.Error != nil {
+		*version = current
+	}
+	return result.Error
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"technical-test/src/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(subscription *model.WebhookSubscription) error
+	FindSubscriptionByID(id int) (model.WebhookSubscription, error)
+	FindSubscriptionsWithPagination(offset, limit int, workflowID int) ([]model.WebhookSubscription, int64, error)
+	FindSubscriptionsForWorkflowTx(tx *gorm.DB, workflowID uint) ([]model.WebhookSubscription, error)
+	DeleteSubscription(id int) error
+	CreateDelivery(delivery *model.WebhookDelivery) error
+	CreateDeliveryTx(tx *gorm.DB, delivery *model.WebhookDelivery) error
+	FindDeliveryByID(subscriptionID, id int) (model.WebhookDelivery, error)
+	FindDeliveriesWithPagination(offset, limit int, subscriptionID int, status string) ([]model.WebhookDelivery, int64, error)
+	FindDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error)
+	UpdateDelivery(delivery *model.WebhookDelivery) error
+	BeginTransaction() *gorm.DB
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *model.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *webhookRepository) FindSubscriptionByID(id int) (model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	err := r.db.First(&subscription, id).Error
+	return subscription, err
+}
+
+func (r *webhookRepository) FindSubscriptionsWithPagination(offset, limit int, workflowID int) ([]model.WebhookSubscription, int64, error) {
+	var subscriptions []model.WebhookSubscription
+	var total int64
+
+	query := r.db.Model(&model.WebhookSubscription{})
+	if workflowID > 0 {
+		query = query.Where("workflow_id = ?", workflowID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return subscriptions, 0, err
+	}
+
+	err := query.
+		Order("id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&subscriptions).Error
+
+	return subscriptions, total, err
+}
+
+// FindSubscriptionsForWorkflowTx returns the subscriptions of the workflow
+// together with the global ones.
+func (r *webhookRepository) FindSubscriptionsForWorkflowTx(tx *gorm.DB, workflowID uint) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+	err := tx.Where("workflow_id IS NULL OR workflow_id = ?", workflowID).
+		Order("id ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteSubscription removes the subscription and its delivery log.
+func (r *webhookRepository) DeleteSubscription(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.WebhookSubscription{}, id).Error
+	})
+}
+
+func (r *webhookRepository) CreateDelivery(delivery *model.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *webhookRepository) CreateDeliveryTx(tx *gorm.DB, delivery *model.WebhookDelivery) error {
+	return tx.Create(delivery).Error
+}
+
+func (r *webhookRepository) FindDeliveryByID(subscriptionID, id int) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := r.db.Where("subscription_id = ?", subscriptionID).First(&delivery, id).Error
+	return delivery, err
+}
+
+func (r *webhookRepository) FindDeliveriesWithPagination(offset, limit int, subscriptionID int, status string) ([]model.WebhookDelivery, int64, error) {
+	var deliveries []model.WebhookDelivery
+	var total int64
+
+	query := r.db.Model(&model.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return deliveries, 0, err
+	}
+
+	err := query.
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&deliveries).Error
+
+	return deliveries, total, err
+}
+
+// FindDueDeliveries returns pending deliveries whose next attempt is due,
+// oldest first.
+func (r *webhookRepository) FindDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+func (r *webhookRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
@@ -4,6 +4,7 @@ import (
 	"technical-test/src/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WorkflowRepository interface {
@@ -12,6 +13,9 @@ type WorkflowRepository interface {
 	FindAll() ([]model.Workflow, error)
 	FindAllWithPagination(offset, limit int, search string) ([]model.Workflow, int64, error)
 	FindByID(id int) (model.Workflow, error)
+	FindByIDWithLock(tx *gorm.DB, id int) (model.Workflow, error)
+	UpdateTx(tx *gorm.DB, workflow *model.Workflow) error
+	BeginTransaction() *gorm.DB
 }
 
 type workflowRepository struct {
@@ -65,3 +69,19 @@ func (r *workflowRepository) FindByID(id int) (model.Workflow, error) {
 	err := r.db.First(&workflow, id).Error
 	return workflow, err
 }
+
+// FindByIDWithLock locks the workflow row so request submissions to the same
+// workflow are serialized.
+func (r *workflowRepository) FindByIDWithLock(tx *gorm.DB, id int) (model.Workflow, error) {
+	var workflow model.Workflow
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&workflow, id).Error
+	return workflow, err
+}
+
+func (r *workflowRepository) UpdateTx(tx *gorm.DB, workflow *model.Workflow) error {
+	return updateVersioned(tx, workflow, &workflow.Version)
+}
+
+func (r *workflowRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
@@ -1,6 +1,10 @@
 package response
 
-import "github.com/gofiber/fiber/v3"
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
 
 // ========================================================
 // Response Structures For Swagger Documentation
@@ -24,15 +28,18 @@ type ResponseError struct {
 
 // LoginResponse represents login response data
 type LoginResponse struct {
-	Token string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	User  UserResponse `json:"user"`
+	Token            string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken     string       `json:"refresh_token" example:"q9vN3x0uVb6k2cT8yJm1sQ4eLh7aWz5pRdFgHjKlXcE"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at" example:"2025-01-08T10:00:00Z"`
+	User             UserResponse `json:"user"`
 }
 
 // UserResponse represents user data in response
 type UserResponse struct {
-	ID    uint   `json:"id" example:"1"`
-	Name  string `json:"name" example:"John Doe"`
-	Email string `json:"email" example:"user@example.com"`
+	ID    uint     `json:"id" example:"1"`
+	Name  string   `json:"name" example:"John Doe"`
+	Email string   `json:"email" example:"user@example.com"`
+	Roles []string `json:"roles" example:"requester,approver"`
 }
 
 // ========================================================
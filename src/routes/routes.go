@@ -3,6 +3,7 @@ package routes
 import (
 	"technical-test/src/handler"
 	"technical-test/src/middleware"
+	"technical-test/src/model"
 	"technical-test/src/repository"
 	"technical-test/src/usecase"
 
@@ -10,24 +11,47 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(app *fiber.App, db *gorm.DB) {
+func SetupRoutes(app *fiber.App, db *gorm.DB, bus *usecase.EventBus) {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	workflowRepo := repository.NewWorkflowRepository(db)
 	stepRepo := repository.NewStepRepository(db)
 	requestRepo := repository.NewRequestRepository(db)
+	approvalRepo := repository.NewApprovalRepository(db)
+	eventRepo := repository.NewRequestEventRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	notificationTemplateRepo := repository.NewNotificationTemplateRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
 
 	// Initialize usecases
-	authUsecase := usecase.NewAuthUsecase(userRepo)
+	authUsecase := usecase.NewAuthUsecase(userRepo, refreshTokenRepo)
 	workflowUsecase := usecase.NewWorkflowUsecase(workflowRepo)
-	stepUsecase := usecase.NewStepUsecase(stepRepo, workflowRepo)
-	requestUsecase := usecase.NewRequestUsecase(requestRepo, stepRepo, workflowRepo)
+	stepUsecase := usecase.NewStepUsecase(stepRepo, workflowRepo, userRepo, groupRepo, roleRepo)
+	groupUsecase := usecase.NewGroupUsecase(groupRepo, userRepo)
+	userUsecase := usecase.NewUserUsecase(userRepo, roleRepo)
+	roleUsecase := usecase.NewRoleUsecase(roleRepo)
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(idempotencyRepo)
+	exchangeRateUsecase := usecase.NewExchangeRateUsecase(exchangeRateRepo)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, workflowRepo)
+	notificationTemplateUsecase := usecase.NewNotificationTemplateUsecase(notificationTemplateRepo, workflowRepo)
+	requestUsecase := usecase.NewRequestUsecase(requestRepo, stepRepo, workflowRepo, approvalRepo, eventRepo, exchangeRateRepo, outboxRepo, bus, userRepo, groupRepo)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authUsecase)
 	workflowHandler := handler.NewWorkflowHandler(workflowUsecase)
 	stepHandler := handler.NewStepHandler(stepUsecase, workflowUsecase)
 	requestHandler := handler.NewRequestHandler(requestUsecase, workflowUsecase)
+	groupHandler := handler.NewGroupHandler(groupUsecase)
+	userHandler := handler.NewUserHandler(userUsecase)
+	roleHandler := handler.NewRoleHandler(roleUsecase)
+	exchangeRateHandler := handler.NewExchangeRateHandler(exchangeRateUsecase)
+	webhookHandler := handler.NewWebhookHandler(webhookUsecase)
+	notificationTemplateHandler := handler.NewNotificationTemplateHandler(notificationTemplateUsecase)
 
 	// Setup routes
 	v1 := app.Group("/v1")
@@ -36,25 +60,86 @@ func SetupRoutes(app *fiber.App, db *gorm.DB) {
 	authGroup := v1.Group("/auth")
 	authGroup.Post("/register", authHandler.Register)
 	authGroup.Post("/login", authHandler.Login)
+	authGroup.Post("/refresh", authHandler.Refresh)
 
 	// Protected routes
 	protected := v1.Group("/", middleware.JWTProtected())
+	adminOnly := middleware.RequireRoles(model.RoleAdmin)
+	designerOnly := middleware.RequireRoles(model.RoleWorkflowDesigner)
+	requesterOnly := middleware.RequireRoles(model.RoleRequester)
+	approverOnly := middleware.RequireRoles(model.RoleApprover)
+	idempotent := middleware.Idempotency(idempotencyUsecase)
 
 	// Workflow routes
 	workflowGroup := protected.Group("/workflows")
-	workflowGroup.Post("/", workflowHandler.CreateWorkflow)
+	workflowGroup.Post("/", designerOnly, workflowHandler.CreateWorkflow)
 	workflowGroup.Get("/", workflowHandler.FindAllWorkflows)
 	workflowGroup.Get("/:workflowId", workflowHandler.GetWorkflowByID)
 
 	// Step routes
-	workflowGroup.Post("/:workflowId/steps", stepHandler.CreateStep)
+	workflowGroup.Post("/:workflowId/steps", designerOnly, stepHandler.CreateStep)
 	workflowGroup.Get("/:workflowId/steps", stepHandler.FindStepsByWorkflowID)
+	workflowGroup.Get("/:workflowId/steps/:stepId", stepHandler.FindStepByID)
+	workflowGroup.Put("/:workflowId/steps/:stepId", designerOnly, stepHandler.UpdateStep)
 
 	// Request routes
 	requestGroup := protected.Group("/requests")
-	requestGroup.Post("/", requestHandler.CreateRequest)
+	requestGroup.Post("/", requesterOnly, idempotent, requestHandler.CreateRequest)
 	requestGroup.Get("/", requestHandler.FindAllRequests)
+	requestGroup.Get("/inbox", requestHandler.FindInbox)
+	requestGroup.Get("/stream", requestHandler.StreamRequestEvents)
+	requestGroup.Post("/bulk-approve", approverOnly, idempotent, requestHandler.BulkApproveRequests)
+	requestGroup.Post("/bulk-reject", approverOnly, idempotent, requestHandler.BulkRejectRequests)
 	requestGroup.Get("/:requestId", requestHandler.GetRequestByID)
-	requestGroup.Post("/:requestId/approve", requestHandler.ApproveRequest)
-	requestGroup.Post("/:requestId/reject", requestHandler.RejectRequest)
+	requestGroup.Post("/:requestId/approve", approverOnly, idempotent, requestHandler.ApproveRequest)
+	requestGroup.Post("/:requestId/reject", approverOnly, idempotent, requestHandler.RejectRequest)
+	requestGroup.Post("/:requestId/cancel", requestHandler.CancelRequest)
+	requestGroup.Post("/:requestId/return", approverOnly, requestHandler.ReturnRequest)
+	requestGroup.Post("/:requestId/resubmit", requestHandler.ResubmitRequest)
+	requestGroup.Get("/:requestId/approvals", requestHandler.FindApprovalsByRequestID)
+	requestGroup.Get("/:requestId/history", requestHandler.FindHistoryByRequestID)
+
+	// Group routes
+	groupGroup := protected.Group("/groups")
+	groupGroup.Post("/", adminOnly, groupHandler.CreateGroup)
+	groupGroup.Get("/", designerOnly, groupHandler.FindAllGroups)
+	groupGroup.Get("/:groupId", designerOnly, groupHandler.GetGroupByID)
+	groupGroup.Post("/:groupId/members", adminOnly, groupHandler.AddGroupMember)
+	groupGroup.Delete("/:groupId/members/:userId", adminOnly, groupHandler.RemoveGroupMember)
+
+	// Exchange rate routes
+	rateGroup := protected.Group("/exchange-rates")
+	rateGroup.Get("/", exchangeRateHandler.FindAllExchangeRates)
+	rateGroup.Post("/", adminOnly, exchangeRateHandler.CreateExchangeRate)
+	rateGroup.Post("/import", adminOnly, exchangeRateHandler.ImportExchangeRates)
+	rateGroup.Delete("/:rateId", adminOnly, exchangeRateHandler.DeleteExchangeRate)
+
+	// Webhook routes (admin only)
+	webhookGroup := protected.Group("/webhooks", adminOnly)
+	webhookGroup.Post("/", webhookHandler.CreateWebhook)
+	webhookGroup.Get("/", webhookHandler.FindAllWebhooks)
+	webhookGroup.Get("/:webhookId", webhookHandler.GetWebhookByID)
+	webhookGroup.Delete("/:webhookId", webhookHandler.DeleteWebhook)
+	webhookGroup.Get("/:webhookId/deliveries", webhookHandler.FindWebhookDeliveries)
+	webhookGroup.Post("/:webhookId/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
+
+	// Notification template routes
+	templateGroup := protected.Group("/notification-templates", designerOnly)
+	templateGroup.Post("/", notificationTemplateHandler.CreateNotificationTemplate)
+	templateGroup.Get("/", notificationTemplateHandler.FindAllNotificationTemplates)
+	templateGroup.Post("/preview", notificationTemplateHandler.PreviewNotificationTemplate)
+	templateGroup.Get("/:templateId", notificationTemplateHandler.GetNotificationTemplateByID)
+	templateGroup.Put("/:templateId", notificationTemplateHandler.UpdateNotificationTemplate)
+	templateGroup.Delete("/:templateId", notificationTemplateHandler.DeleteNotificationTemplate)
+
+	// User routes (admin only)
+	userGroup := protected.Group("/users", adminOnly)
+	userGroup.Get("/", userHandler.FindAllUsers)
+	userGroup.Get("/:userId", userHandler.GetUserByID)
+	userGroup.Put("/:userId/roles", userHandler.UpdateUserRoles)
+
+	// Role routes (admin only)
+	roleGroup := protected.Group("/roles", adminOnly)
+	roleGroup.Post("/", roleHandler.CreateRole)
+	roleGroup.Get("/", roleHandler.FindAllRoles)
 }
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"technical-test/src/repository"
+
+	"gorm.io/gorm"
+)
+
+// Step actors name the principals allowed to act on a step:
+//
+//	user:<id>     a specific user
+//	role:<name>   any user holding the role
+//	group:<name>  any member of the group
+//
+// A bare name such as "Manager" is treated as a role. Steps store the
+// canonical <kind>:<value> form (see actorPrincipal.String), which is what the
+// inbox and request list match on.
+const (
+	ActorKindUser  = "user"
+	ActorKindRole  = "role"
+	ActorKindGroup = "group"
+)
+
+var (
+	ErrInvalidActor   = errors.New("actor must be user:<id>, role:<name>, group:<name> or a role name")
+	ErrActorNotFound  = errors.New("actor does not match an existing user, role or group")
+	ErrActorForbidden = errors.New("user is not allowed to act on the current step")
+)
+
+type actorPrincipal struct {
+	Kind  string
+	Value string
+}
+
+func parseActor(actor string) (actorPrincipal, error) {
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return actorPrincipal{}, ErrInvalidActor
+	}
+
+	kind, value, found := strings.Cut(actor, ":")
+	if !found {
+		return actorPrincipal{Kind: ActorKindRole, Value: actor}, nil
+	}
+
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return actorPrincipal{}, ErrInvalidActor
+	}
+
+	switch kind {
+	case ActorKindUser:
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			return actorPrincipal{}, ErrInvalidActor
+		}
+		value = strconv.FormatUint(id, 10)
+	case ActorKindRole, ActorKindGroup:
+	default:
+		return actorPrincipal{}, ErrInvalidActor
+	}
+
+	return actorPrincipal{Kind: kind, Value: value}, nil
+}
+
+func (p actorPrincipal) String() string {
+	return p.Kind + ":" + p.Value
+}
+
+// userPrincipals holds everything a user can be matched against as an actor.
+type userPrincipals struct {
+	UserID uint
+	Roles  []string
+	Groups []string
+}
+
+func (p userPrincipals) matches(actor actorPrincipal) bool {
+	switch actor.Kind {
+	case ActorKindUser:
+		return actor.Value == strconv.FormatUint(uint64(p.UserID), 10)
+	case ActorKindRole:
+		return containsFold(p.Roles, actor.Value)
+	case ActorKindGroup:
+		return containsFold(p.Groups, actor.Value)
+	}
+	return false
+}
+
+// actorKeys lists the lower-cased canonical actor strings that resolve to the
+// user. Bare role names are included for steps saved before actors were
+// stored in canonical form.
+func (p userPrincipals) actorKeys() []string {
+	keys := []string{ActorKindUser + ":" + strconv.FormatUint(uint64(p.UserID), 10)}
+	for _, role := range p.Roles {
+		role = strings.ToLower(role)
+		keys = append(keys, role, ActorKindRole+":"+role)
+	}
+	for _, group := range p.Groups {
+		keys = append(keys, ActorKindGroup+":"+strings.ToLower(group))
+	}
+	return keys
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type actorResolver struct {
+	userRepo  repository.UserRepository
+	groupRepo repository.GroupRepository
+}
+
+func (r actorResolver) principalsTx(tx *gorm.DB, userID uint) (userPrincipals, error) {
+	principals := userPrincipals{UserID: userID}
+
+	roles, err := r.userRepo.FindRolesTx(tx, userID)
+	if err != nil {
+		return principals, err
+	}
+	principals.Roles = roles
+
+	groups, err := r.groupRepo.FindNamesByUserIDTx(tx, userID)
+	if err != nil {
+		return principals, err
+	}
+	principals.Groups = groups
+
+	return principals, nil
+}
+
+// canActTx reports whether the user resolves to the given step actor.
+func (r actorResolver) canActTx(tx *gorm.DB, actor string, userID uint) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+
+	principal, err := parseActor(actor)
+	if err != nil {
+		return false, err
+	}
+
+	principals, err := r.principalsTx(tx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return principals.matches(principal), nil
+}
+
+// eligibleUserIDsTx lists every user the step actor currently resolves to.
+func (r actorResolver) eligibleUserIDsTx(tx *gorm.DB, actor string) ([]uint, error) {
+	principal, err := parseActor(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	switch principal.Kind {
+	case ActorKindUser:
+		id, _ := strconv.ParseUint(principal.Value, 10, 64)
+		return []uint{uint(id)}, nil
+	case ActorKindGroup:
+		return r.groupRepo.FindMemberIDsByNameTx(tx, principal.Value)
+	default:
+		return r.userRepo.FindIDsByRoleTx(tx, principal.Value)
+	}
+}
@@ -1,6 +1,10 @@
 package usecase
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"technical-test/src/config"
 	"technical-test/src/model"
@@ -14,22 +18,35 @@ import (
 
 type AuthUsecase interface {
 	Register(name, email, password string) (model.User, error)
-	Login(email, password string) (string, model.User, error)
+	Login(email, password string) (AuthTokens, model.User, error)
+	Refresh(refreshToken string) (AuthTokens, model.User, error)
+}
+
+// AuthTokens is what a login or refresh hands out: a short-lived access token
+// (JWT) and the refresh token to exchange for the next pair.
+type AuthTokens struct {
+	AccessToken      string
+	RefreshToken     string
+	RefreshExpiresAt time.Time
 }
 
 type authUsecase struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
 }
 
 var (
-	ErrInvalidCredentials = errors.New("invalid email or password")
-	ErrEmailExists        = errors.New("email already registered")
-	ErrJWTSecretMissing   = errors.New("jwt secret is not configured")
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrEmailExists         = errors.New("email already registered")
+	ErrJWTSecretMissing    = errors.New("jwt secret is not configured")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used; all sessions of this login were revoked")
 )
 
-func NewAuthUsecase(userRepo repository.UserRepository) AuthUsecase {
+func NewAuthUsecase(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository) AuthUsecase {
 	return &authUsecase{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
 	}
 }
 
@@ -46,40 +63,212 @@ func (uc *authUsecase) Register(name, email, password string) (model.User, error
 		return model.User{}, err
 	}
 
+	// Every account can submit requests; the very first account also becomes
+	// admin so that roles can be handed out afterwards. The user count is
+	// taken under a lock in the same transaction as the insert, so two
+	// concurrent first registrations cannot both become admin.
+	tx := uc.userRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	total, err := uc.userRepo.CountWithLock(tx)
+	if err != nil {
+		tx.Rollback()
+		return model.User{}, err
+	}
+
+	roles := []model.UserRole{{Role: model.RoleRequester}}
+	if total == 0 {
+		roles = append(roles, model.UserRole{Role: model.RoleAdmin})
+	}
+
 	user := model.User{
 		Name:         name,
 		Email:        email,
 		PasswordHash: string(hash),
+		Roles:        roles,
+	}
+
+	if err := uc.userRepo.CreateTx(tx, &user); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return model.User{}, ErrEmailExists
+		}
+		return model.User{}, err
 	}
 
-	if err := uc.userRepo.Create(&user); err != nil {
+	if err := tx.Commit().Error; err != nil {
 		return model.User{}, err
 	}
 
 	return user, nil
 }
 
-func (uc *authUsecase) Login(email, password string) (string, model.User, error) {
+// Login checks the credentials and starts a new refresh token family.
+func (uc *authUsecase) Login(email, password string) (AuthTokens, model.User, error) {
 	user, err := uc.userRepo.FindByEmail(email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", model.User{}, ErrInvalidCredentials
+			return AuthTokens{}, model.User{}, ErrInvalidCredentials
 		}
-		return "", model.User{}, err
+		return AuthTokens{}, model.User{}, err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", model.User{}, ErrInvalidCredentials
+		return AuthTokens{}, model.User{}, ErrInvalidCredentials
+	}
+
+	accessToken, err := generateJWT(user.ID, user.Email, user.RoleNames())
+	if err != nil {
+		return AuthTokens{}, model.User{}, err
+	}
+
+	familyID, err := randomToken(16)
+	if err != nil {
+		return AuthTokens{}, model.User{}, err
+	}
+	refreshToken, record, err := newRefreshToken(user.ID, familyID, time.Now())
+	if err != nil {
+		return AuthTokens{}, model.User{}, err
+	}
+	if err := uc.refreshTokenRepo.Create(&record); err != nil {
+		return AuthTokens{}, model.User{}, err
+	}
+
+	tokens := AuthTokens{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: record.ExpiresAt,
+	}
+	return tokens, user, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token of the same family; the presented token cannot be used again. If a
+// token that was already exchanged comes back, either the client or an
+// attacker holds a stolen copy, so the whole family is revoked and the user
+// has to log in again.
+func (uc *authUsecase) Refresh(refreshToken string) (AuthTokens, model.User, error) {
+	now := time.Now()
+
+	tx := uc.refreshTokenRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	current, err := uc.refreshTokenRepo.FindByHashWithLock(tx, hashRefreshToken(refreshToken))
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return AuthTokens{}, model.User{}, ErrInvalidRefreshToken
+		}
+		return AuthTokens{}, model.User{}, err
 	}
 
-	token, err := generateJWT(user.ID, user.Email)
+	if current.RevokedAt != nil || !now.Before(current.ExpiresAt) {
+		tx.Rollback()
+		return AuthTokens{}, model.User{}, ErrInvalidRefreshToken
+	}
+
+	if current.RotatedAt != nil {
+		// The revocation is committed even though the refresh fails
+		if err := uc.refreshTokenRepo.RevokeFamilyTx(tx, current.FamilyID, now); err != nil {
+			tx.Rollback()
+			return AuthTokens{}, model.User{}, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return AuthTokens{}, model.User{}, err
+		}
+		return AuthTokens{}, model.User{}, ErrRefreshTokenReused
+	}
+
+	// Roles are read again so role changes apply from the next refresh
+	user, err := uc.userRepo.FindByIDTx(tx, int(current.UserID))
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return AuthTokens{}, model.User{}, ErrInvalidRefreshToken
+		}
+		return AuthTokens{}, model.User{}, err
+	}
+
+	current.RotatedAt = &now
+	if err := uc.refreshTokenRepo.UpdateTx(tx, &current); err != nil {
+		tx.Rollback()
+		return AuthTokens{}, model.User{}, err
+	}
+
+	nextToken, next, err := newRefreshToken(user.ID, current.FamilyID, now)
+	if err != nil {
+		tx.Rollback()
+		return AuthTokens{}, model.User{}, err
+	}
+	if err := uc.refreshTokenRepo.CreateTx(tx, &next); err != nil {
+		tx.Rollback()
+		return AuthTokens{}, model.User{}, err
+	}
+
+	accessToken, err := generateJWT(user.ID, user.Email, user.RoleNames())
 	if err != nil {
-		return "", model.User{}, err
+		tx.Rollback()
+		return AuthTokens{}, model.User{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return AuthTokens{}, model.User{}, err
+	}
+
+	tokens := AuthTokens{
+		AccessToken:      accessToken,
+		RefreshToken:     nextToken,
+		RefreshExpiresAt: next.ExpiresAt,
+	}
+	return tokens, user, nil
+}
+
+// newRefreshToken returns a new random refresh token and the record storing
+// its hash, valid for JWT_REFRESH_EXP_DAYS from now.
+func newRefreshToken(userID uint, familyID string, now time.Time) (string, model.RefreshToken, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", model.RefreshToken{}, err
+	}
+
+	expDays := config.JWTRefreshExp
+	if expDays <= 0 {
+		expDays = 7
+	}
+
+	record := model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(token),
+		ExpiresAt: now.AddDate(0, 0, expDays),
+	}
+	return token, record, nil
+}
+
+// hashRefreshToken hashes with plain SHA-256: the tokens are random, so unlike
+// passwords they need no salt or slow hash, and the hash can be looked up.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
 	}
-	return token, user, nil
+	return base64.RawURLEncoding.EncodeToString(buf), nil
 }
 
-func generateJWT(userID uint, email string) (string, error) {
+func generateJWT(userID uint, email string, roles []string) (string, error) {
 	if config.JWTSecret == "" {
 		return "", ErrJWTSecretMissing
 	}
@@ -92,6 +281,7 @@ func generateJWT(userID uint, email string) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":   userID,
 		"email": email,
+		"roles": roles,
 		"exp":   time.Now().Add(time.Duration(expMinutes) * time.Minute).Unix(),
 		"iat":   time.Now().Unix(),
 	}
@@ -0,0 +1,16 @@
+package usecase
+
+import "time"
+
+// retryBackoff returns the wait before the next attempt after attempts failed
+// ones: base, then doubled after every failure, capped at max.
+func retryBackoff(attempts uint, base, max time.Duration) time.Duration {
+	backoff := base
+	for i := uint(1); i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
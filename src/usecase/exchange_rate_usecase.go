@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// EffectiveDateLayout is the format of effective dates in the API and in
+// imported files.
+const EffectiveDateLayout = "2006-01-02"
+
+// rateScale matches the decimal(20,8) rate column.
+const rateScale = 8
+
+type ExchangeRateUsecase interface {
+	CreateExchangeRate(fromCurrency, toCurrency string, rate decimal.Decimal, effectiveDate time.Time) (model.ExchangeRate, error)
+	FindExchangeRatesWithPagination(page, pageSize int, fromCurrency, toCurrency string) ([]model.ExchangeRate, int64, error)
+	DeleteExchangeRate(id int) error
+	ImportExchangeRates(file io.Reader) (int, error)
+}
+
+type exchangeRateUsecase struct {
+	rateRepo repository.ExchangeRateRepository
+}
+
+var (
+	ErrInvalidRate          = errors.New("rate must be greater than 0 with at most 8 decimal places")
+	ErrSameCurrency         = errors.New("from and to currency must differ")
+	ErrExchangeRateExists   = errors.New("a rate for this currency pair and effective date already exists")
+	ErrInvalidRateFile      = errors.New("invalid exchange rate file")
+	ErrExchangeRateNotFound = errors.New("no exchange rate to the workflow currency is valid on the submission date")
+)
+
+func NewExchangeRateUsecase(rateRepo repository.ExchangeRateRepository) ExchangeRateUsecase {
+	return &exchangeRateUsecase{
+		rateRepo: rateRepo,
+	}
+}
+
+func (uc *exchangeRateUsecase) CreateExchangeRate(fromCurrency, toCurrency string, rate decimal.Decimal, effectiveDate time.Time) (model.ExchangeRate, error) {
+	exchangeRate, err := newExchangeRate(fromCurrency, toCurrency, rate, effectiveDate)
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+
+	if err := uc.rateRepo.Create(&exchangeRate); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return model.ExchangeRate{}, ErrExchangeRateExists
+		}
+		return model.ExchangeRate{}, err
+	}
+
+	return exchangeRate, nil
+}
+
+func (uc *exchangeRateUsecase) FindExchangeRatesWithPagination(page, pageSize int, fromCurrency, toCurrency string) ([]model.ExchangeRate, int64, error) {
+	offset := (page - 1) * pageSize
+	return uc.rateRepo.FindAllWithPagination(offset, pageSize, strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency))
+}
+
+func (uc *exchangeRateUsecase) DeleteExchangeRate(id int) error {
+	if _, err := uc.rateRepo.FindByID(id); err != nil {
+		return err
+	}
+	return uc.rateRepo.Delete(id)
+}
+
+// ImportExchangeRates loads a CSV file with the header
+// from_currency,to_currency,rate,effective_date. Rows for a pair and date that
+// already exist replace the stored rate, so a file can be imported again. The
+// import is all or nothing.
+func (uc *exchangeRateUsecase) ImportExchangeRates(file io.Reader) (int, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidRateFile, err)
+	}
+	expected := []string{"from_currency", "to_currency", "rate", "effective_date"}
+	for i, column := range expected {
+		if strings.ToLower(strings.TrimSpace(header[i])) != column {
+			return 0, fmt.Errorf("%w: header must be %s", ErrInvalidRateFile, strings.Join(expected, ","))
+		}
+	}
+
+	var rates []model.ExchangeRate
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidRateFile, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		rate, err := decimal.NewFromString(strings.TrimSpace(record[2]))
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: %v", ErrInvalidRateFile, line, ErrInvalidRate)
+		}
+		effectiveDate, err := time.Parse(EffectiveDateLayout, strings.TrimSpace(record[3]))
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: effective_date must be %s", ErrInvalidRateFile, line, EffectiveDateLayout)
+		}
+
+		exchangeRate, err := newExchangeRate(record[0], record[1], rate, effectiveDate)
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: %v", ErrInvalidRateFile, line, err)
+		}
+		rates = append(rates, exchangeRate)
+	}
+
+	tx := uc.rateRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for i := range rates {
+		if err := uc.rateRepo.UpsertTx(tx, &rates[i]); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+func newExchangeRate(fromCurrency, toCurrency string, rate decimal.Decimal, effectiveDate time.Time) (model.ExchangeRate, error) {
+	from, err := normalizeCurrency(fromCurrency, "")
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+	to, err := normalizeCurrency(toCurrency, "")
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+	if from == to {
+		return model.ExchangeRate{}, ErrSameCurrency
+	}
+	if !rate.IsPositive() || !rate.Equal(rate.Truncate(rateScale)) {
+		return model.ExchangeRate{}, ErrInvalidRate
+	}
+
+	return model.ExchangeRate{
+		FromCurrency:  from,
+		ToCurrency:    to,
+		Rate:          rate,
+		EffectiveDate: effectiveDay(effectiveDate),
+	}, nil
+}
+
+// effectiveDay drops the time of day so rates and submission dates compare by
+// calendar date.
+func effectiveDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// convertTx converts amount into the currency to at the rate valid on date. A
+// rate stored for the opposite direction is used inverted. It returns the
+// converted amount and the from->to rate applied.
+func convertTx(tx *gorm.DB, rates repository.ExchangeRateRepository, amount decimal.Decimal, from, to string, date time.Time) (decimal.Decimal, decimal.Decimal, error) {
+	if from == to {
+		return amount, decimal.NewFromInt(1), nil
+	}
+
+	day := effectiveDay(date)
+	var rate decimal.Decimal
+	direct, err := rates.FindEffectiveTx(tx, from, to, day)
+	switch {
+	case err == nil:
+		rate = direct.Rate
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		inverse, err := rates.FindEffectiveTx(tx, to, from, day)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return decimal.Zero, decimal.Zero, ErrExchangeRateNotFound
+		} else if err != nil {
+			return decimal.Zero, decimal.Zero, err
+		}
+		rate = decimal.NewFromInt(1).DivRound(inverse.Rate, rateScale)
+	default:
+		return decimal.Zero, decimal.Zero, err
+	}
+
+	return amount.Mul(rate).Round(amountScale), rate, nil
+}
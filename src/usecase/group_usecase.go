@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"errors"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+
+	"gorm.io/gorm"
+)
+
+type GroupUsecase interface {
+	CreateGroup(name string) (model.Group, error)
+	FindAllGroupsWithPagination(page, pageSize int, search string) ([]model.Group, int64, error)
+	GetGroupByID(id int) (model.Group, error)
+	AddMember(groupID, userID int) (model.GroupMember, error)
+	RemoveMember(groupID, userID int) error
+}
+
+type groupUsecase struct {
+	groupRepo repository.GroupRepository
+	userRepo  repository.UserRepository
+}
+
+var (
+	ErrGroupNameExists   = errors.New("group name already exists")
+	ErrGroupMemberExists = errors.New("user is already a member of this group")
+)
+
+func NewGroupUsecase(groupRepo repository.GroupRepository, userRepo repository.UserRepository) GroupUsecase {
+	return &groupUsecase{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (uc *groupUsecase) CreateGroup(name string) (model.Group, error) {
+	existing, err := uc.groupRepo.FindByName(name)
+	if err == nil && existing.ID != 0 {
+		return model.Group{}, ErrGroupNameExists
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Group{}, err
+	}
+
+	group := model.Group{Name: name}
+	if err := uc.groupRepo.Create(&group); err != nil {
+		return model.Group{}, err
+	}
+
+	return group, nil
+}
+
+func (uc *groupUsecase) FindAllGroupsWithPagination(page, pageSize int, search string) ([]model.Group, int64, error) {
+	offset := (page - 1) * pageSize
+	return uc.groupRepo.FindAllWithPagination(offset, pageSize, search)
+}
+
+func (uc *groupUsecase) GetGroupByID(id int) (model.Group, error) {
+	return uc.groupRepo.FindByID(id)
+}
+
+func (uc *groupUsecase) AddMember(groupID, userID int) (model.GroupMember, error) {
+	group, err := uc.groupRepo.FindByID(groupID)
+	if err != nil {
+		return model.GroupMember{}, err
+	}
+
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return model.GroupMember{}, err
+	}
+
+	for _, member := range group.Members {
+		if member.UserID == user.ID {
+			return model.GroupMember{}, ErrGroupMemberExists
+		}
+	}
+
+	member := model.GroupMember{
+		GroupID: group.ID,
+		UserID:  user.ID,
+	}
+	if err := uc.groupRepo.AddMember(&member); err != nil {
+		return model.GroupMember{}, err
+	}
+
+	return member, nil
+}
+
+func (uc *groupUsecase) RemoveMember(groupID, userID int) error {
+	group, err := uc.groupRepo.FindByID(groupID)
+	if err != nil {
+		return err
+	}
+
+	return uc.groupRepo.RemoveMember(group.ID, uint(userID))
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"errors"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyTTL is how long a stored response is replayed. After that the
+// key can be reused for a new call.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+type IdempotencyUsecase interface {
+	Begin(userID uint, key, fingerprint string) (model.IdempotencyKey, bool, error)
+	Complete(record model.IdempotencyKey, statusCode int, etag string, body []byte) error
+	Release(record model.IdempotencyKey) error
+}
+
+type idempotencyUsecase struct {
+	idempotencyRepo repository.IdempotencyRepository
+}
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+func NewIdempotencyUsecase(idempotencyRepo repository.IdempotencyRepository) IdempotencyUsecase {
+	return &idempotencyUsecase{
+		idempotencyRepo: idempotencyRepo,
+	}
+}
+
+// Begin claims the key for the user. It reports true when the key already holds
+// a completed response for the same fingerprint, which should be replayed
+// instead of running the call again.
+func (uc *idempotencyUsecase) Begin(userID uint, key, fingerprint string) (model.IdempotencyKey, bool, error) {
+	record := model.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint}
+
+	err := uc.idempotencyRepo.Create(&record)
+	if err == nil {
+		return record, false, nil
+	}
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return record, false, err
+	}
+
+	existing, err := uc.idempotencyRepo.FindByUserAndKey(userID, key)
+	if err != nil {
+		return record, false, err
+	}
+
+	if time.Since(existing.CreatedAt) > IdempotencyKeyTTL {
+		if err := uc.idempotencyRepo.Delete(existing.ID); err != nil {
+			return record, false, err
+		}
+		if err := uc.idempotencyRepo.Create(&record); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return record, false, ErrIdempotencyKeyInProgress
+			}
+			return record, false, err
+		}
+		return record, false, nil
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return existing, false, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return existing, false, ErrIdempotencyKeyInProgress
+	}
+
+	return existing, true, nil
+}
+
+func (uc *idempotencyUsecase) Complete(record model.IdempotencyKey, statusCode int, etag string, body []byte) error {
+	record.StatusCode = statusCode
+	record.ETag = etag
+	record.Response = append([]byte(nil), body...)
+	return uc.idempotencyRepo.Update(&record)
+}
+
+// Release forgets a claimed key whose call failed, so the client can retry it.
+func (uc *idempotencyUsecase) Release(record model.IdempotencyKey) error {
+	return uc.idempotencyRepo.Delete(record.ID)
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCurrency is used for workflows created without a currency and for
+// requests that do not name one.
+const DefaultCurrency = "IDR"
+
+// amountScale matches the decimal(20,4) amount columns; amounts with more
+// fractional digits would be rounded by the database.
+const amountScale = 4
+
+var (
+	ErrInvalidCurrency  = errors.New("currency must be a 3-letter ISO 4217 code")
+	ErrCurrencyMismatch = errors.New("currency does not match the workflow currency")
+	ErrAmountPrecision  = errors.New("amount supports at most 4 decimal places")
+)
+
+// normalizeCurrency upper-cases an ISO 4217 code, falling back to fallback when
+// code is empty.
+func normalizeCurrency(code, fallback string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		code = fallback
+	}
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
+
+func validateAmount(amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+	if !amount.Equal(amount.Truncate(amountScale)) {
+		return ErrAmountPrecision
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"technical-test/src/mailer"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"text/template"
+
+	"github.com/gofiber/fiber/v3/log"
+	"gorm.io/gorm"
+)
+
+// Notifications sent by email:
+//
+//	STEP_ASSIGNED     to the users who can act on the step a request now waits on
+//	REQUEST_APPROVED  to the requester
+//	REQUEST_REJECTED  to the requester
+//	REQUEST_RETURNED  to the requester, when the request is returned to them
+const (
+	NotificationStepAssigned = "STEP_ASSIGNED"
+	NotificationApproved     = "REQUEST_APPROVED"
+	NotificationRejected     = "REQUEST_REJECTED"
+	NotificationReturned     = "REQUEST_RETURNED"
+)
+
+// NotificationData is what notification templates are rendered with.
+type NotificationData struct {
+	Request   model.Request
+	Workflow  model.Workflow
+	Step      model.Step // step the request waits on
+	Recipient NotificationUser
+	Actor     NotificationUser // user who made the change, empty for automatic changes
+	Comment   string           // comment of the decision that returned or rejected the request
+}
+
+// NotificationUser is the part of a user templates can see, so a template
+// cannot leak fields such as the password hash.
+type NotificationUser struct {
+	ID    uint
+	Name  string
+	Email string
+}
+
+func newNotificationUser(user model.User) NotificationUser {
+	return NotificationUser{ID: user.ID, Name: user.Name, Email: user.Email}
+}
+
+// errNotificationRender marks a template that cannot be rendered with the
+// notification data. Sending again would fail the same way.
+var errNotificationRender = errors.New("notification cannot be rendered")
+
+// isPermanentNotificationError reports whether retrying the notification
+// cannot help: something it refers to is gone or its template is broken.
+func isPermanentNotificationError(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, errNotificationRender)
+}
+
+type notificationTemplate struct {
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// defaultNotificationTemplates are used for the kinds without a stored global
+// default or workflow override.
+var defaultNotificationTemplates = map[string]notificationTemplate{
+	NotificationStepAssigned: {
+		Subject: "Request #{{.Request.ID}} is waiting for your approval",
+		TextBody: "Hello {{.Recipient.Name}},\n\n" +
+			"Request #{{.Request.ID}} in {{.Workflow.Name}} for {{.Request.Amount}} {{.Request.Currency}} is waiting for your decision at step {{.Step.Level}}.\n",
+	},
+	NotificationApproved: {
+		Subject: "Request #{{.Request.ID}} was approved",
+		TextBody: "Hello {{.Recipient.Name}},\n\n" +
+			"Your request #{{.Request.ID}} in {{.Workflow.Name}} for {{.Request.Amount}} {{.Request.Currency}} was approved.\n",
+	},
+	NotificationRejected: {
+		Subject: "Request #{{.Request.ID}} was rejected",
+		TextBody: "Hello {{.Recipient.Name}},\n\n" +
+			"Your request #{{.Request.ID}} in {{.Workflow.Name}} for {{.Request.Amount}} {{.Request.Currency}} was rejected{{with .Actor.Name}} by {{.}}{{end}}.\n" +
+			"{{with .Request.RejectionReason}}\nReason: {{.}}\n{{end}}" +
+			"{{with .Comment}}\nComment: {{.}}\n{{end}}",
+	},
+	NotificationReturned: {
+		Subject: "Request #{{.Request.ID}} was returned for revision",
+		TextBody: "Hello {{.Recipient.Name}},\n\n" +
+			"Your request #{{.Request.ID}} in {{.Workflow.Name}} was returned for revision{{with .Actor.Name}} by {{.}}{{end}}. Please update and resubmit it.\n" +
+			"{{with .Comment}}\nComment: {{.}}\n{{end}}",
+	},
+}
+
+type notificationSink struct {
+	mailer       mailer.Mailer
+	templateRepo repository.NotificationTemplateRepository
+	requestRepo  repository.RequestRepository
+	stepRepo     repository.StepRepository
+	workflowRepo repository.WorkflowRepository
+	approvalRepo repository.ApprovalRepository
+	userRepo     repository.UserRepository
+	actors       actorResolver
+}
+
+// NewNotificationSink returns the outbox sink that emails the users concerned
+// by a request event. It runs after the change committed, so a failing mail
+// server never rolls back a decision; the event is retried instead. Errors
+// that a retry cannot fix are logged and the notification dropped.
+func NewNotificationSink(mailer mailer.Mailer, templateRepo repository.NotificationTemplateRepository, requestRepo repository.RequestRepository, stepRepo repository.StepRepository, workflowRepo repository.WorkflowRepository, approvalRepo repository.ApprovalRepository, userRepo repository.UserRepository, groupRepo repository.GroupRepository) OutboxSink {
+	return &notificationSink{
+		mailer:       mailer,
+		templateRepo: templateRepo,
+		requestRepo:  requestRepo,
+		stepRepo:     stepRepo,
+		workflowRepo: workflowRepo,
+		approvalRepo: approvalRepo,
+		userRepo:     userRepo,
+		actors:       actorResolver{userRepo: userRepo, groupRepo: groupRepo},
+	}
+}
+
+func (s *notificationSink) Name() string {
+	return "email"
+}
+
+func (s *notificationSink) Publish(event model.OutboxEvent) error {
+	err := s.publish(event)
+	if isPermanentNotificationError(err) {
+		log.Warnf("Dropping email notification of outbox event %d: %v", event.ID, err)
+		return nil
+	}
+	return err
+}
+
+func (s *notificationSink) publish(event model.OutboxEvent) error {
+	var message RequestEventMessage
+	if err := json.Unmarshal(event.Payload, &message); err != nil {
+		return err
+	}
+
+	var after requestSnapshot
+	if len(message.After) > 0 {
+		if err := json.Unmarshal(message.After, &after); err != nil {
+			return err
+		}
+	}
+
+	request, err := s.requestRepo.FindByID(int(message.RequestID))
+	if err != nil {
+		return err
+	}
+
+	data := NotificationData{Request: request}
+	if data.Workflow, err = s.workflowRepo.FindByID(int(request.WorkflowID)); err != nil {
+		return err
+	}
+	if message.ActorID != nil {
+		actor, err := s.userRepo.FindByID(int(*message.ActorID))
+		if err != nil {
+			return err
+		}
+		data.Actor = newNotificationUser(actor)
+	}
+
+	switch {
+	case message.Type == model.RequestEventApproved:
+		return s.notifyRequester(NotificationApproved, data)
+	case message.Type == model.RequestEventRejected:
+		data.Comment = s.decisionComment(request.ID, "REJECTED")
+		return s.notifyRequester(NotificationRejected, data)
+	case message.Type == model.RequestEventReturned && after.Status == "RETURNED":
+		data.Comment = s.decisionComment(request.ID, "RETURNED")
+		return s.notifyRequester(NotificationReturned, data)
+	case assignsStep(message.Type) && after.Status == "PENDING":
+		// Only the event that left the request on its current step notifies,
+		// so a request passing several levels in one change is announced once.
+		if request.Status != "PENDING" || request.CurrentStep != after.CurrentStep {
+			return nil
+		}
+		return s.notifyApprovers(data)
+	}
+
+	return nil
+}
+
+func assignsStep(eventType string) bool {
+	switch eventType {
+	case model.RequestEventCreated, model.RequestEventStepAdvanced, model.RequestEventReturned, model.RequestEventResubmitted:
+		return true
+	}
+	return false
+}
+
+func (s *notificationSink) notifyRequester(kind string, data NotificationData) error {
+	if data.Request.RequesterID == 0 {
+		return nil
+	}
+
+	requester, err := s.userRepo.FindByID(int(data.Request.RequesterID))
+	if err != nil {
+		return err
+	}
+
+	data.Recipient = newNotificationUser(requester)
+	return s.send(kind, data)
+}
+
+// notifyApprovers emails every user who can act on the current step. Sending
+// is best effort: the event is only retried when no approver got the mail and
+// at least one failure is worth retrying, so approvers who already got it do
+// not get it again because another one failed.
+func (s *notificationSink) notifyApprovers(data NotificationData) error {
+	step, err := s.stepRepo.FindByLevelAndWorkflowID(data.Request.CurrentStep, int(data.Request.WorkflowID))
+	if err != nil {
+		return err
+	}
+	data.Step = step
+
+	tx := s.requestRepo.BeginTransaction()
+	userIDs, err := s.actors.eligibleUserIDsTx(tx, step.Actor)
+	tx.Rollback()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	delivered := 0
+	for _, userID := range userIDs {
+		recipient, err := s.userRepo.FindByID(int(userID))
+		if err == nil {
+			data.Recipient = newNotificationUser(recipient)
+			err = s.send(NotificationStepAssigned, data)
+		}
+
+		switch {
+		case err == nil:
+			delivered++
+		case isPermanentNotificationError(err):
+			log.Warnf("Dropping %s notification of request %d to user %d: %v", NotificationStepAssigned, data.Request.ID, userID, err)
+		default:
+			errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+		}
+	}
+
+	if delivered == 0 && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		log.Errorf("Failed to send %s notification of request %d: %v", NotificationStepAssigned, data.Request.ID, err)
+	}
+	return nil
+}
+
+// decisionComment returns the comment of the latest decision of the kind on
+// the request.
+func (s *notificationSink) decisionComment(requestID uint, decision string) string {
+	approvals, err := s.approvalRepo.FindByRequestID(int(requestID))
+	if err != nil {
+		return ""
+	}
+
+	for i := len(approvals) - 1; i >= 0; i-- {
+		if approvals[i].Decision == decision {
+			return approvals[i].Comment
+		}
+	}
+	return ""
+}
+
+func (s *notificationSink) send(kind string, data NotificationData) error {
+	if data.Recipient.Email == "" {
+		return nil
+	}
+
+	tpl, err := effectiveNotificationTemplate(s.templateRepo, kind, data.Request.WorkflowID)
+	if err != nil {
+		return err
+	}
+
+	message, err := renderNotification(tpl, data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNotificationRender, err)
+	}
+	message.To = []string{data.Recipient.Email}
+
+	return s.mailer.Send(message)
+}
+
+// effectiveNotificationTemplate returns the template of the kind used for the
+// workflow: its override, the stored global default or the built-in text.
+func effectiveNotificationTemplate(templateRepo repository.NotificationTemplateRepository, kind string, workflowID uint) (notificationTemplate, error) {
+	stored, err := templateRepo.FindEffective(kind, workflowID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultNotificationTemplates[kind], nil
+	} else if err != nil {
+		return notificationTemplate{}, err
+	}
+
+	return notificationTemplate{
+		Subject:  stored.Subject,
+		TextBody: stored.TextBody,
+		HTMLBody: stored.HTMLBody,
+	}, nil
+}
+
+func renderNotification(tpl notificationTemplate, data NotificationData) (mailer.Message, error) {
+	subject, err := renderText(tpl.Subject, data)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("subject: %w", err)
+	}
+
+	textBody, err := renderText(tpl.TextBody, data)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("text_body: %w", err)
+	}
+
+	var htmlBody string
+	if tpl.HTMLBody != "" {
+		if htmlBody, err = renderHTML(tpl.HTMLBody, data); err != nil {
+			return mailer.Message{}, fmt.Errorf("html_body: %w", err)
+		}
+	}
+
+	return mailer.Message{Subject: subject, TextBody: textBody, HTMLBody: htmlBody}, nil
+}
+
+func renderText(text string, data NotificationData) (string, error) {
+	tpl, err := template.New("notification").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderHTML renders with html/template so request data such as comments is
+// escaped in the HTML alternative.
+func renderHTML(text string, data NotificationData) (string, error) {
+	tpl, err := htmltemplate.New("notification").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"technical-test/src/mailer"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type NotificationTemplateUsecase interface {
+	CreateTemplate(kind string, workflowID *uint, subject, textBody, htmlBody string, userID uint) (model.NotificationTemplate, error)
+	FindTemplatesWithPagination(page, pageSize int, kind string, workflowID int) ([]model.NotificationTemplate, int64, error)
+	GetTemplateByID(id int) (model.NotificationTemplate, error)
+	UpdateTemplate(id int, version uint, subject, textBody, htmlBody string) (model.NotificationTemplate, error)
+	DeleteTemplate(id int, version uint) error
+	PreviewTemplate(kind string, workflowID *uint, subject, textBody, htmlBody string) (mailer.Message, error)
+}
+
+type notificationTemplateUsecase struct {
+	templateRepo repository.NotificationTemplateRepository
+	workflowRepo repository.WorkflowRepository
+}
+
+var (
+	ErrInvalidNotificationKind     = errors.New("notification kind must be STEP_ASSIGNED, REQUEST_APPROVED, REQUEST_REJECTED or REQUEST_RETURNED")
+	ErrInvalidNotificationTemplate = errors.New("invalid notification template")
+	ErrNotificationTemplateExists  = errors.New("a template for this kind and workflow already exists")
+)
+
+func NewNotificationTemplateUsecase(templateRepo repository.NotificationTemplateRepository, workflowRepo repository.WorkflowRepository) NotificationTemplateUsecase {
+	return &notificationTemplateUsecase{
+		templateRepo: templateRepo,
+		workflowRepo: workflowRepo,
+	}
+}
+
+// CreateTemplate stores the global default of a kind when workflowID is nil,
+// otherwise the override of the workflow. Each scope holds one template per
+// kind.
+func (uc *notificationTemplateUsecase) CreateTemplate(kind string, workflowID *uint, subject, textBody, htmlBody string, userID uint) (model.NotificationTemplate, error) {
+	kind, err := normalizeNotificationKind(kind)
+	if err != nil {
+		return model.NotificationTemplate{}, err
+	}
+
+	workflow, err := uc.findWorkflow(workflowID)
+	if err != nil {
+		return model.NotificationTemplate{}, err
+	}
+
+	tpl := notificationTemplate{Subject: subject, TextBody: textBody, HTMLBody: htmlBody}
+	if err := validateNotificationTemplate(kind, tpl, workflow); err != nil {
+		return model.NotificationTemplate{}, err
+	}
+
+	// The unique index does not cover global defaults, whose workflow_id is NULL
+	if _, err := uc.templateRepo.FindByScope(kind, workflowID); err == nil {
+		return model.NotificationTemplate{}, ErrNotificationTemplateExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotificationTemplate{}, err
+	}
+
+	template := model.NotificationTemplate{
+		WorkflowID: workflowID,
+		Kind:       kind,
+		Subject:    subject,
+		TextBody:   textBody,
+		HTMLBody:   htmlBody,
+		CreatedBy:  userID,
+	}
+	if err := uc.templateRepo.Create(&template); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return model.NotificationTemplate{}, ErrNotificationTemplateExists
+		}
+		return model.NotificationTemplate{}, err
+	}
+
+	return template, nil
+}
+
+func (uc *notificationTemplateUsecase) FindTemplatesWithPagination(page, pageSize int, kind string, workflowID int) ([]model.NotificationTemplate, int64, error) {
+	offset := (page - 1) * pageSize
+	return uc.templateRepo.FindAllWithPagination(offset, pageSize, strings.ToUpper(kind), workflowID)
+}
+
+func (uc *notificationTemplateUsecase) GetTemplateByID(id int) (model.NotificationTemplate, error) {
+	return uc.templateRepo.FindByID(id)
+}
+
+// UpdateTemplate replaces the texts of the template if it is still at
+// version; 0 skips the check.
+func (uc *notificationTemplateUsecase) UpdateTemplate(id int, version uint, subject, textBody, htmlBody string) (model.NotificationTemplate, error) {
+	template, err := uc.templateRepo.FindByID(id)
+	if err != nil {
+		return model.NotificationTemplate{}, err
+	}
+
+	if err := checkVersion(version, template.Version); err != nil {
+		return model.NotificationTemplate{}, err
+	}
+
+	workflow, err := uc.findWorkflow(template.WorkflowID)
+	if err != nil {
+		return model.NotificationTemplate{}, err
+	}
+
+	tpl := notificationTemplate{Subject: subject, TextBody: textBody, HTMLBody: htmlBody}
+	if err := validateNotificationTemplate(template.Kind, tpl, workflow); err != nil {
+		return model.NotificationTemplate{}, err
+	}
+
+	template.Subject = subject
+	template.TextBody = textBody
+	template.HTMLBody = htmlBody
+	if err := uc.templateRepo.Update(&template); err != nil {
+		if errors.Is(err, repository.ErrStaleVersion) {
+			return model.NotificationTemplate{}, ErrVersionMismatch
+		}
+		return model.NotificationTemplate{}, err
+	}
+
+	return template, nil
+}
+
+// DeleteTemplate removes the template if it is still at version; 0 skips the
+// check.
+func (uc *notificationTemplateUsecase) DeleteTemplate(id int, version uint) error {
+	template, err := uc.templateRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkVersion(version, template.Version); err != nil {
+		return err
+	}
+
+	if err := uc.templateRepo.Delete(id, version); err != nil {
+		if errors.Is(err, repository.ErrStaleVersion) {
+			return ErrVersionMismatch
+		}
+		return err
+	}
+	return nil
+}
+
+// PreviewTemplate renders a template against a sample request without sending
+// it. When subject, textBody and htmlBody are all empty, the template the
+// workflow currently uses for the kind is rendered instead.
+func (uc *notificationTemplateUsecase) PreviewTemplate(kind string, workflowID *uint, subject, textBody, htmlBody string) (mailer.Message, error) {
+	kind, err := normalizeNotificationKind(kind)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	workflow, err := uc.findWorkflow(workflowID)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	tpl := notificationTemplate{Subject: subject, TextBody: textBody, HTMLBody: htmlBody}
+	if subject == "" && textBody == "" && htmlBody == "" {
+		if tpl, err = effectiveNotificationTemplate(uc.templateRepo, kind, workflow.ID); err != nil {
+			return mailer.Message{}, err
+		}
+	} else if err := validateNotificationTemplate(kind, tpl, workflow); err != nil {
+		return mailer.Message{}, err
+	}
+
+	data := sampleNotificationData(kind, workflow)[0]
+	message, err := renderNotification(tpl, data)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("%w: %v", ErrInvalidNotificationTemplate, err)
+	}
+	message.To = []string{data.Recipient.Email}
+
+	return message, nil
+}
+
+// findWorkflow returns the workflow a template applies to, or a sample
+// workflow for global defaults.
+func (uc *notificationTemplateUsecase) findWorkflow(workflowID *uint) (model.Workflow, error) {
+	if workflowID == nil {
+		return model.Workflow{Name: "Purchase Order", SubmissionMode: model.SubmissionModeMerge, Currency: DefaultCurrency}, nil
+	}
+	return uc.workflowRepo.FindByID(int(*workflowID))
+}
+
+func normalizeNotificationKind(kind string) (string, error) {
+	kind = strings.ToUpper(strings.TrimSpace(kind))
+	if _, ok := defaultNotificationTemplates[kind]; !ok {
+		return "", ErrInvalidNotificationKind
+	}
+	return kind, nil
+}
+
+// validateNotificationTemplate rejects templates that do not parse or that
+// fail to render with any of the samples of the kind, so a mistake shows up
+// when the template is saved rather than when the first email fails.
+func validateNotificationTemplate(kind string, tpl notificationTemplate, workflow model.Workflow) error {
+	if strings.TrimSpace(tpl.Subject) == "" {
+		return fmt.Errorf("%w: subject is required", ErrInvalidNotificationTemplate)
+	}
+	if strings.TrimSpace(tpl.TextBody) == "" {
+		return fmt.Errorf("%w: text_body is required", ErrInvalidNotificationTemplate)
+	}
+
+	for _, data := range sampleNotificationData(kind, workflow) {
+		if _, err := renderNotification(tpl, data); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidNotificationTemplate, err)
+		}
+	}
+	return nil
+}
+
+// sampleNotificationStatus is the status a request has when a notification of
+// the kind is sent.
+var sampleNotificationStatus = map[string]string{
+	NotificationStepAssigned: "PENDING",
+	NotificationApproved:     "APPROVED",
+	NotificationRejected:     "REJECTED",
+	NotificationReturned:     "RETURNED",
+}
+
+// sampleNotificationData returns the data templates of the kind are validated
+// with. The first sample fills every field and is the one previews use. The
+// others are shaped like real notifications that leave fields empty: an
+// automatic change has no actor, most decisions no comment or reason, a request
+// may have no metadata, and one in a foreign currency carries a conversion
+// rate. The step is only set for STEP_ASSIGNED, as when emails are sent.
+func sampleNotificationData(kind string, workflow model.Workflow) []NotificationData {
+	status := sampleNotificationStatus[kind]
+	amount := decimal.NewFromInt(1500000)
+
+	var step model.Step
+	if kind == NotificationStepAssigned {
+		step = model.Step{WorkflowID: workflow.ID, Level: 1, Actor: "group:finance", Version: 1}
+	}
+
+	full := NotificationData{
+		Request: model.Request{
+			ID:              1024,
+			WorkflowID:      workflow.ID,
+			RequesterID:     1,
+			CurrentStep:     1,
+			Status:          status,
+			Amount:          amount,
+			Currency:        workflow.Currency,
+			BaseAmount:      amount,
+			BaseCurrency:    workflow.Currency,
+			ExchangeRate:    decimal.NewFromInt(1),
+			RejectionReason: "Over budget",
+			SubmissionMode:  workflow.SubmissionMode,
+			Metadata:        datatypes.JSON(`{"invoice": "INV-1024"}`),
+			Version:         1,
+			CreatedAt:       time.Now(),
+		},
+		Workflow:  workflow,
+		Step:      step,
+		Recipient: NotificationUser{ID: 1, Name: "Jane Doe", Email: "jane.doe@example.com"},
+		Actor:     NotificationUser{ID: 2, Name: "John Smith", Email: "john.smith@example.com"},
+		Comment:   "Please attach the invoice",
+	}
+	if status == "PENDING" {
+		full.Request.PendingWorkflowID = &full.Request.WorkflowID
+	}
+
+	minimal := full
+	minimal.Request.ID = 1
+	minimal.Request.RejectionReason = ""
+	minimal.Request.Metadata = nil
+	minimal.Request.PendingWorkflowID = nil
+	minimal.Recipient = NotificationUser{ID: 1, Email: "jane.doe@example.com"}
+	minimal.Actor = NotificationUser{}
+	minimal.Comment = ""
+
+	foreign := minimal
+	foreign.Request.Amount = decimal.NewFromInt(100)
+	foreign.Request.Currency = "USD"
+	foreign.Request.ExchangeRate = decimal.NewFromInt(15000)
+	if workflow.Currency == "USD" {
+		foreign.Request.Currency = "EUR"
+		foreign.Request.ExchangeRate = decimal.RequireFromString("1.08")
+	}
+	foreign.Request.BaseAmount = foreign.Request.Amount.Mul(foreign.Request.ExchangeRate)
+
+	return []NotificationData{full, minimal, foreign}
+}
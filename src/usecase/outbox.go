@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v3/log"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// An event that a sink refused is published again after 5s, 10s, 20s, ... (at
+// most five minutes apart) until every sink accepted it; it is never dropped.
+const (
+	outboxBaseBackoff   = 5 * time.Second
+	outboxMaxBackoff    = 5 * time.Minute
+	outboxDispatchBatch = 100
+)
+
+// Delivered events are pruned at most this often by Run.
+const outboxPruneInterval = time.Hour
+
+// RequestEventMessage is the payload of the outbox event written for every
+// request event. It is also the body of webhook calls.
+type RequestEventMessage struct {
+	EventID    uint           `json:"event_id"`
+	Type       string         `json:"type"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	RequestID  uint           `json:"request_id"`
+	WorkflowID uint           `json:"workflow_id"`
+	ActorID    *uint          `json:"actor_id"`
+	Before     datatypes.JSON `json:"before"`
+	After      datatypes.JSON `json:"after"`
+}
+
+// OutboxSink receives committed outbox events. Events are published at least
+// once: a sink gets an event again when it returned an error, or when the
+// dispatcher stopped before recording that the sink accepted it.
+type OutboxSink interface {
+	Name() string
+	Publish(event model.OutboxEvent) error
+}
+
+type OutboxDispatcher interface {
+	DispatchPending(now time.Time) (int, error)
+	PruneDelivered(before time.Time) (int64, error)
+	Run(ctx context.Context, interval, retention time.Duration)
+}
+
+type outboxDispatcher struct {
+	outboxRepo repository.OutboxRepository
+	sinks      []OutboxSink
+}
+
+func NewOutboxDispatcher(outboxRepo repository.OutboxRepository, sinks ...OutboxSink) OutboxDispatcher {
+	return &outboxDispatcher{
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+	}
+}
+
+// writeOutboxTx adds the request event to the outbox in the transaction that
+// recorded it.
+func writeOutboxTx(tx *gorm.DB, outboxRepo repository.OutboxRepository, event model.RequestEvent, workflowID uint) error {
+	payload, err := json.Marshal(RequestEventMessage{
+		EventID:    event.ID,
+		Type:       event.Type,
+		OccurredAt: event.CreatedAt,
+		RequestID:  event.RequestID,
+		WorkflowID: workflowID,
+		ActorID:    event.ActorID,
+		Before:     event.Before,
+		After:      event.After,
+	})
+	if err != nil {
+		return err
+	}
+
+	return outboxRepo.CreateTx(tx, &model.OutboxEvent{
+		RequestID:     event.RequestID,
+		WorkflowID:    workflowID,
+		Type:          event.Type,
+		Payload:       datatypes.JSON(payload),
+		NextAttemptAt: event.CreatedAt,
+	})
+}
+
+// DispatchPending publishes every undelivered event due at now and returns how
+// many were handled.
+func (d *outboxDispatcher) DispatchPending(now time.Time) (int, error) {
+	events, err := d.outboxRepo.FindPending(now, outboxDispatchBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		if err := d.publish(&events[i], now); err != nil {
+			return i, err
+		}
+	}
+
+	return len(events), nil
+}
+
+// PruneDelivered removes events that were delivered before the given time.
+func (d *outboxDispatcher) PruneDelivered(before time.Time) (int64, error) {
+	return d.outboxRepo.DeleteDeliveredBefore(before)
+}
+
+// Run publishes pending events every interval until ctx is done. Events
+// delivered longer than retention ago are pruned once an hour.
+func (d *outboxDispatcher) Run(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastPruned time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := d.DispatchPending(now); err != nil {
+				log.Errorf("Failed to dispatch outbox events: %v", err)
+			}
+
+			if now.Sub(lastPruned) >= outboxPruneInterval {
+				lastPruned = now
+				if _, err := d.PruneDelivered(now.Add(-retention)); err != nil {
+					log.Errorf("Failed to prune delivered outbox events: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// publish hands the event to the sinks that have not accepted it yet. It is
+// marked delivered once all of them did, otherwise retried later.
+func (d *outboxDispatcher) publish(event *model.OutboxEvent, now time.Time) error {
+	var published []string
+	if event.PublishedTo != "" {
+		published = strings.Split(event.PublishedTo, ",")
+	}
+
+	var failures []string
+	for _, sink := range d.sinks {
+		if containsString(published, sink.Name()) {
+			continue
+		}
+		if err := sink.Publish(*event); err != nil {
+			failures = append(failures, sink.Name()+": "+err.Error())
+			continue
+		}
+		published = append(published, sink.Name())
+	}
+
+	event.Attempts++
+	event.PublishedTo = strings.Join(published, ",")
+	if len(failures) == 0 {
+		event.DeliveredAt = &now
+		event.LastError = ""
+	} else {
+		event.LastError = strings.Join(failures, "; ")
+		if len(event.LastError) > 1000 {
+			event.LastError = event.LastError[:1000]
+		}
+		event.NextAttemptAt = now.Add(retryBackoff(event.Attempts, outboxBaseBackoff, outboxMaxBackoff))
+	}
+
+	return d.outboxRepo.Update(event)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type logSink struct{}
+
+// NewLogSink returns a sink that writes every event to the application log.
+func NewLogSink() OutboxSink {
+	return logSink{}
+}
+
+func (logSink) Name() string {
+	return "log"
+}
+
+func (logSink) Publish(event model.OutboxEvent) error {
+	log.Infof("Outbox event %d: %s request=%d workflow=%d", event.ID, event.Type, event.RequestID, event.WorkflowID)
+	return nil
+}
+
+// EventBus is the in-process sink: it passes every event to the handlers
+// subscribed when the event is published. Handlers run on the dispatcher
+// goroutine and must not block.
+type EventBus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]func(model.OutboxEvent)
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: map[int]func(model.OutboxEvent){}}
+}
+
+func (b *EventBus) Name() string {
+	return "bus"
+}
+
+// Subscribe registers handler and returns the function that removes it.
+func (b *EventBus) Subscribe(handler func(model.OutboxEvent)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+func (b *EventBus) Publish(event model.OutboxEvent) error {
+	b.mu.RLock()
+	handlers := make([]func(model.OutboxEvent), 0, len(b.handlers))
+	for _, handler := range b.handlers {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+	return nil
+}
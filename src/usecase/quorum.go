@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+// Quorum rules decide how many distinct approvers a step needs before the
+// request moves on:
+//
+//	any     one approval from the step actor (default)
+//	all     every user the step actor resolves to
+//	n_of_m  "required" approvals, where each approver counts for its weight
+//	        in "weights" (keyed by user ID, default 1)
+const (
+	QuorumAny  = "any"
+	QuorumAll  = "all"
+	QuorumNOfM = "n_of_m"
+)
+
+var (
+	ErrInvalidQuorum     = errors.New("quorum rule must be any, all or n_of_m with a required value greater than 0, and weights must be greater than 0")
+	ErrQuorumUnreachable = errors.New("quorum cannot be reached by the users the step actor resolves to")
+	ErrDuplicateApproval = errors.New("user has already approved this step")
+)
+
+type quorumRule struct {
+	Rule     string         `json:"rule"`
+	Required int            `json:"required"`
+	Weights  map[string]int `json:"weights"`
+}
+
+func (q *quorumRule) validate() error {
+	if q == nil {
+		return nil
+	}
+
+	switch q.Rule {
+	case "", QuorumAny, QuorumAll:
+	case QuorumNOfM:
+		if q.Required <= 0 {
+			return ErrInvalidQuorum
+		}
+	default:
+		return ErrInvalidQuorum
+	}
+
+	for userID, weight := range q.Weights {
+		if _, err := strconv.ParseUint(userID, 10, 64); err != nil || weight <= 0 {
+			return ErrInvalidQuorum
+		}
+	}
+
+	return nil
+}
+
+// checkReachable makes sure the users the step actor resolves to can satisfy
+// the rule: an all rule needs at least one of them, and an n_of_m rule needs
+// their combined weight to cover the required value.
+func (q *quorumRule) checkReachable(eligibleIDs []uint) error {
+	if q == nil {
+		return nil
+	}
+
+	switch q.Rule {
+	case QuorumAll:
+		if len(eligibleIDs) == 0 {
+			return ErrQuorumUnreachable
+		}
+	case QuorumNOfM:
+		var total int
+		for _, id := range eligibleIDs {
+			total += q.weightOf(id)
+		}
+		if total < q.Required {
+			return ErrQuorumUnreachable
+		}
+	}
+
+	return nil
+}
+
+func (q *quorumRule) weightOf(userID uint) int {
+	if q != nil {
+		if weight, ok := q.Weights[strconv.FormatUint(uint64(userID), 10)]; ok {
+			return weight
+		}
+	}
+	return 1
+}
+
+// quorumReachedTx reports whether the approvals recorded on the request's
+// current level satisfy the quorum of the step.
+func (uc *requestUsecase) quorumReachedTx(tx *gorm.DB, request model.Request, step model.Step, quorum *quorumRule) (bool, error) {
+	approverIDs, err := uc.approvalRepo.FindApproverIDsTx(tx, request.ID, request.CurrentStep)
+	if err != nil {
+		return false, err
+	}
+
+	rule := QuorumAny
+	if quorum != nil && quorum.Rule != "" {
+		rule = quorum.Rule
+	}
+
+	switch rule {
+	case QuorumAll:
+		eligibleIDs, err := uc.actors.eligibleUserIDsTx(tx, step.Actor)
+		if err != nil {
+			return false, err
+		}
+		if len(eligibleIDs) == 0 {
+			return false, nil
+		}
+
+		approved := make(map[uint]bool, len(approverIDs))
+		for _, id := range approverIDs {
+			approved[id] = true
+		}
+		for _, id := range eligibleIDs {
+			if !approved[id] {
+				return false, nil
+			}
+		}
+		return true, nil
+	case QuorumNOfM:
+		var total int
+		for _, id := range approverIDs {
+			total += quorum.weightOf(id)
+		}
+		return total >= quorum.Required, nil
+	default:
+		return len(approverIDs) > 0, nil
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+// Viewer identifies the authenticated user reading requests. Admins see every
+// request; other users only see requests they submitted, decided on, or are
+// allowed to act on at the current step.
+type Viewer struct {
+	UserID uint
+	Admin  bool
+}
+
+// findVisibleRequest loads a request and hides it behind gorm.ErrRecordNotFound
+// when the viewer is not allowed to see it, so its existence is not leaked.
+func (uc *requestUsecase) findVisibleRequest(id int, viewer Viewer) (model.Request, error) {
+	request, err := uc.requestRepo.FindByID(id)
+	if err != nil || viewer.Admin || (viewer.UserID != 0 && request.RequesterID == viewer.UserID) {
+		return request, err
+	}
+
+	tx := uc.requestRepo.BeginTransaction()
+	defer tx.Rollback()
+
+	visible, err := uc.canViewTx(tx, request, viewer.UserID)
+	if err != nil {
+		return model.Request{}, err
+	}
+	if !visible {
+		return model.Request{}, gorm.ErrRecordNotFound
+	}
+
+	return request, nil
+}
+
+func (uc *requestUsecase) canViewTx(tx *gorm.DB, request model.Request, userID uint) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+
+	decided, err := uc.approvalRepo.ExistsForUserTx(tx, request.ID, userID)
+	if err != nil || decided {
+		return decided, err
+	}
+
+	if request.Status != "PENDING" {
+		return false, nil
+	}
+
+	return uc.canActOnCurrentStepTx(tx, request, userID)
+}
+
+// canActOnCurrentStepTx reports whether the user resolves to the actor of the
+// step the request is waiting on.
+func (uc *requestUsecase) canActOnCurrentStepTx(tx *gorm.DB, request model.Request, userID uint) (bool, error) {
+	step, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, request.CurrentStep, int(request.WorkflowID))
+	if err != nil {
+		return false, err
+	}
+
+	return uc.actors.canActTx(tx, step.Actor, userID)
+}
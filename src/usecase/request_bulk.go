@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"errors"
+	"technical-test/src/model"
+
+	"gorm.io/gorm"
+)
+
+// Per-item outcomes of a bulk decision.
+const (
+	BulkResultOK              = "ok"
+	BulkResultBelowThreshold  = "below_threshold"
+	BulkResultVersionMismatch = "version_mismatch"
+	BulkResultNotPending      = "not_pending"
+	BulkResultForbidden       = "forbidden"
+	BulkResultNotFound        = "not_found"
+	BulkResultFailed          = "failed"
+)
+
+// MaxBulkRequests caps how many requests one bulk call may decide on.
+const MaxBulkRequests = 100
+
+var (
+	ErrBulkEmpty    = errors.New("at least one request ID is required")
+	ErrBulkTooLarge = errors.New("too many request IDs in one bulk call")
+)
+
+// BulkItem is one request in a bulk decision. Version is the request version
+// the caller last saw (the ETag of a previous GET); 0 skips the check and the
+// decision applies to whatever the request looks like when it is locked.
+type BulkItem struct {
+	ID      int  `json:"id"`
+	Version uint `json:"version"`
+}
+
+// BulkResult is the outcome of one request in a bulk approve or reject.
+type BulkResult struct {
+	RequestID int            `json:"request_id"`
+	Result    string         `json:"result"`
+	Error     string         `json:"error,omitempty"`
+	Request   *model.Request `json:"request,omitempty"`
+}
+
+// BulkApproveRequests approves each request with the same locked logic as
+// ApproveRequest, one transaction per item, so a bad item only fails itself.
+// An API step whose amount threshold is not met records nothing and is
+// reported as below_threshold rather than ok.
+func (uc *requestUsecase) BulkApproveRequests(items []BulkItem, userID uint, comment string) ([]BulkResult, error) {
+	return runBulk(items, func(item BulkItem) (model.Request, string, error) {
+		request, approved, err := uc.approveRequest(item.ID, item.Version, userID, comment)
+		if err == nil && !approved {
+			return request, BulkResultBelowThreshold, nil
+		}
+		return request, BulkResultOK, err
+	})
+}
+
+// BulkRejectRequests rejects each request with the same locked logic as
+// RejectRequest, using one reason and comment for the whole batch.
+func (uc *requestUsecase) BulkRejectRequests(items []BulkItem, userID uint, reason, comment string) ([]BulkResult, error) {
+	return runBulk(items, func(item BulkItem) (model.Request, string, error) {
+		request, err := uc.RejectRequest(item.ID, item.Version, userID, reason, comment)
+		return request, BulkResultOK, err
+	})
+}
+
+// runBulk applies decide to every distinct request ID in order and collects
+// the outcome of each. Only the first occurrence of an ID is used.
+func runBulk(items []BulkItem, decide func(item BulkItem) (model.Request, string, error)) ([]BulkResult, error) {
+	if len(items) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	if len(items) > MaxBulkRequests {
+		return nil, ErrBulkTooLarge
+	}
+
+	results := make([]BulkResult, 0, len(items))
+	seen := make(map[int]bool, len(items))
+	for _, item := range items {
+		if seen[item.ID] {
+			continue
+		}
+		seen[item.ID] = true
+
+		request, result, err := decide(item)
+		if err != nil {
+			results = append(results, BulkResult{RequestID: item.ID, Result: bulkResultOf(err), Error: err.Error()})
+			continue
+		}
+		results = append(results, BulkResult{RequestID: item.ID, Result: result, Request: &request})
+	}
+	return results, nil
+}
+
+func bulkResultOf(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return BulkResultNotFound
+	case errors.Is(err, ErrVersionMismatch):
+		return BulkResultVersionMismatch
+	case errors.Is(err, ErrInvalidRequestState):
+		return BulkResultNotPending
+	case errors.Is(err, ErrActorForbidden):
+		return BulkResultForbidden
+	}
+	return BulkResultFailed
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"encoding/json"
+	"technical-test/src/model"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// requestSnapshot is the part of a request recorded as before/after values
+// in its event history.
+type requestSnapshot struct {
+	Status      string          `json:"status"`
+	CurrentStep uint            `json:"current_step"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+func snapshotOf(request model.Request) requestSnapshot {
+	return requestSnapshot{
+		Status:      request.Status,
+		CurrentStep: request.CurrentStep,
+		Amount:      request.Amount,
+	}
+}
+
+// recordEventTx appends an event to the history of the request and to the
+// outbox, so it is published only if the transaction commits.
+func (uc *requestUsecase) recordEventTx(tx *gorm.DB, request model.Request, eventType string, actorID uint, before, after *requestSnapshot) error {
+	event := model.RequestEvent{
+		RequestID: request.ID,
+		Type:      eventType,
+	}
+	if actorID != 0 {
+		event.ActorID = &actorID
+	}
+
+	var err error
+	if event.Before, err = marshalSnapshot(before); err != nil {
+		return err
+	}
+	if event.After, err = marshalSnapshot(after); err != nil {
+		return err
+	}
+
+	if err := uc.eventRepo.CreateTx(tx, &event); err != nil {
+		return err
+	}
+
+	return writeOutboxTx(tx, uc.outboxRepo, event, request.WorkflowID)
+}
+
+// recordProgressTx records the step and status changes between two states of
+// the same request: STEP_ADVANCED when the level moved, then APPROVED,
+// REJECTED or CANCELLED when the request reached a final status.
+func (uc *requestUsecase) recordProgressTx(tx *gorm.DB, request model.Request, actorID uint, before, after requestSnapshot) error {
+	if after.CurrentStep != before.CurrentStep {
+		if err := uc.recordEventTx(tx, request, model.RequestEventStepAdvanced, actorID, &before, &after); err != nil {
+			return err
+		}
+	}
+
+	if after.Status != before.Status {
+		eventType := ""
+		switch after.Status {
+		case "APPROVED":
+			eventType = model.RequestEventApproved
+		case "REJECTED":
+			eventType = model.RequestEventRejected
+		case "CANCELLED":
+			eventType = model.RequestEventCancelled
+		}
+		if eventType != "" {
+			return uc.recordEventTx(tx, request, eventType, actorID, &before, &after)
+		}
+	}
+
+	return nil
+}
+
+func marshalSnapshot(snapshot *requestSnapshot) (datatypes.JSON, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(data), nil
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"errors"
+	"technical-test/src/model"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// A request can be sent back instead of rejected:
+//
+//	requester  the request becomes RETURNED until the requester resubmits it;
+//	           it then restarts from the chosen level (level 1 by default)
+//	level      the request stays PENDING and moves back to an earlier level
+//
+// Decisions recorded from the restart level onwards are superseded, so those
+// levels are approved again from scratch.
+const (
+	ReturnTargetRequester = "requester"
+	ReturnTargetLevel     = "level"
+)
+
+var (
+	ErrInvalidReturnTarget = errors.New("return target must be the requester or an earlier step level")
+	ErrRequestNotReturned  = errors.New("request has not been returned for revision")
+)
+
+func (uc *requestUsecase) ReturnRequest(id int, version uint, userID uint, target string, level uint, comment string) (model.Request, error) {
+	var request model.Request
+
+	tx := uc.requestRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	request, err := uc.requestRepo.FindByIDWithLock(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := checkVersion(version, request.Version); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if request.Status != "PENDING" {
+		tx.Rollback()
+		return request, ErrInvalidRequestState
+	}
+
+	step, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, request.CurrentStep, int(request.WorkflowID))
+	if err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := uc.checkActorTx(tx, step, userID); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	before := snapshotOf(request)
+	restartLevel := level
+
+	switch target {
+	case ReturnTargetRequester:
+		if restartLevel == 0 {
+			restartLevel = 1
+		}
+		if restartLevel > request.CurrentStep {
+			tx.Rollback()
+			return request, ErrInvalidReturnTarget
+		}
+		request.Status = "RETURNED"
+		request.ResumeLevel = restartLevel
+	case ReturnTargetLevel:
+		if restartLevel == 0 || restartLevel >= request.CurrentStep {
+			tx.Rollback()
+			return request, ErrInvalidReturnTarget
+		}
+		request.CurrentStep = restartLevel
+	default:
+		tx.Rollback()
+		return request, ErrInvalidReturnTarget
+	}
+
+	approval := model.Approval{
+		RequestID: request.ID,
+		StepLevel: before.CurrentStep,
+		UserID:    userID,
+		Decision:  "RETURNED",
+		Comment:   comment,
+	}
+	if err := uc.approvalRepo.CreateTx(tx, &approval); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := uc.approvalRepo.SupersedeFromLevelTx(tx, request.ID, restartLevel); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := uc.requestRepo.UpdateTx(tx, &request); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	after := snapshotOf(request)
+	if err := uc.recordEventTx(tx, request, model.RequestEventReturned, userID, &before, &after); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return request, err
+	}
+
+	return request, nil
+}
+
+// ResubmitRequest puts a RETURNED request back into the approval flow, with an
+// optional new amount or metadata, starting at the level chosen on return.
+func (uc *requestUsecase) ResubmitRequest(id int, version uint, userID uint, amount *decimal.Decimal, metadata datatypes.JSON) (model.Request, error) {
+	if amount != nil {
+		if err := validateAmount(*amount); err != nil {
+			return model.Request{}, err
+		}
+	}
+
+	if err := validateMetadata(metadata); err != nil {
+		return model.Request{}, err
+	}
+
+	var request model.Request
+	var err error
+	for attempt := 0; attempt < createRequestAttempts; attempt++ {
+		request, err = uc.resubmitRequest(id, version, userID, amount, metadata)
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			break
+		}
+	}
+	return request, err
+}
+
+func (uc *requestUsecase) resubmitRequest(id int, version uint, userID uint, amount *decimal.Decimal, metadata datatypes.JSON) (model.Request, error) {
+	tx := uc.requestRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	request, err := uc.requestRepo.FindByIDWithLock(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := checkVersion(version, request.Version); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if request.RequesterID == 0 || request.RequesterID != userID {
+		tx.Rollback()
+		return request, ErrNotRequester
+	}
+
+	if request.Status != "RETURNED" {
+		tx.Rollback()
+		return request, ErrRequestNotReturned
+	}
+
+	before := snapshotOf(request)
+
+	if amount != nil {
+		baseAmount, rate, err := convertTx(tx, uc.rateRepo, *amount, request.Currency, request.BaseCurrency, time.Now())
+		if err != nil {
+			tx.Rollback()
+			return request, err
+		}
+		request.Amount = *amount
+		request.BaseAmount = baseAmount
+		request.ExchangeRate = rate
+	}
+	if len(metadata) > 0 {
+		request.Metadata = metadata
+	}
+
+	// A merge request gave up the pending slot of its workflow when it was
+	// returned. It takes the slot back, or goes on as a separate request when
+	// a newer submission holds the slot by now, so the workflow keeps a single
+	// merge target.
+	if request.SubmissionMode != model.SubmissionModeSeparate {
+		_, err := uc.requestRepo.FindPendingByWorkflowIDWithLock(tx, int(request.WorkflowID))
+		if err == nil {
+			request.SubmissionMode = model.SubmissionModeSeparate
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			pendingWorkflowID := request.WorkflowID
+			request.PendingWorkflowID = &pendingWorkflowID
+		} else {
+			tx.Rollback()
+			return request, err
+		}
+	}
+
+	request.Status = "PENDING"
+	request.CurrentStep = request.ResumeLevel
+	if request.CurrentStep == 0 {
+		request.CurrentStep = 1
+	}
+	request.ResumeLevel = 0
+	resubmitted := snapshotOf(request)
+
+	if err := uc.settleCurrentStepTx(tx, &request); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := uc.requestRepo.UpdateTx(tx, &request); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := uc.recordEventTx(tx, request, model.RequestEventResubmitted, userID, &before, &resubmitted); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := uc.recordProgressTx(tx, request, userID, resubmitted, snapshotOf(request)); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return request, err
+	}
+
+	return request, nil
+}
@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"technical-test/src/model"
+	"time"
+)
+
+// A stream replays at most streamReplayLimit missed events on resume, and
+// buffers up to streamBufferSize live events while the client is slow.
+const (
+	streamReplayLimit = 1000
+	streamBufferSize  = 64
+)
+
+// StreamEventReset is the type of the message that ends a replay cut short
+// by streamReplayLimit. Its EventID is the latest event at that point; the
+// client should reload the requests it shows and continue from there.
+const StreamEventReset = "RESET"
+
+var ErrStreamLagged = errors.New("stream fell behind the published events")
+
+// EventSubscriber hands published outbox events to in-process handlers.
+// EventBus implements it.
+type EventSubscriber interface {
+	Subscribe(handler func(model.OutboxEvent)) func()
+}
+
+// StreamFilter narrows the events of a request stream. Zero values match
+// every event.
+type StreamFilter struct {
+	WorkflowID   uint
+	Status       string // status of the request right after the event
+	AssignedToMe bool   // only requests still pending on a step the viewer can act on
+}
+
+// RequestStream delivers the request events a viewer may see: first the ones
+// recorded after the resume point, then live ones as they are published.
+type RequestStream interface {
+	// Next waits up to timeout for the next event; ok is false on timeout.
+	// ErrStreamLagged means live events were dropped and the client should
+	// reconnect from the last event it received.
+	Next(timeout time.Duration) (message RequestEventMessage, ok bool, err error)
+	Close()
+}
+
+type requestStream struct {
+	uc          *requestUsecase
+	filter      StreamFilter
+	viewer      Viewer
+	principals  userPrincipals
+	replay      []RequestEventMessage
+	replayed    map[uint]bool
+	skipThrough uint
+	live        chan model.OutboxEvent
+	lagged      chan struct{}
+	unsubscribe func()
+}
+
+// OpenRequestStream subscribes to live events before reading the missed ones,
+// so no event falls between the two. lastEventID is the request event ID the
+// client received last, 0 for live events only. When more than
+// streamReplayLimit events were missed, the replay ends with a
+// StreamEventReset message instead of the rest of them.
+//
+// The roles and groups of the viewer are resolved once, when the stream is
+// opened; a client sees changes to them after it reconnects.
+func (uc *requestUsecase) OpenRequestStream(lastEventID uint, filter StreamFilter, viewer Viewer) (RequestStream, error) {
+	filter.Status = strings.ToUpper(filter.Status)
+
+	stream := &requestStream{
+		uc:       uc,
+		filter:   filter,
+		viewer:   viewer,
+		replayed: map[uint]bool{},
+		live:     make(chan model.OutboxEvent, streamBufferSize),
+		lagged:   make(chan struct{}, 1),
+	}
+
+	if viewer.UserID != 0 {
+		tx := uc.requestRepo.BeginTransaction()
+		principals, err := uc.actors.principalsTx(tx, viewer.UserID)
+		tx.Rollback()
+		if err != nil {
+			return nil, err
+		}
+		stream.principals = principals
+	}
+
+	stream.unsubscribe = uc.events.Subscribe(func(event model.OutboxEvent) {
+		select {
+		case stream.live <- event:
+		default:
+			select {
+			case stream.lagged <- struct{}{}:
+			default:
+			}
+		}
+	})
+
+	if lastEventID > 0 {
+		if err := stream.loadReplay(lastEventID); err != nil {
+			stream.Close()
+			return nil, err
+		}
+	}
+
+	return stream, nil
+}
+
+// loadReplay queues the events recorded after lastEventID that pass the
+// filter. If the limit cut the replay short, it queues a reset message
+// carrying the latest event ID and drops live events up to that ID, since the
+// client reloads the requests it shows anyway.
+func (s *requestStream) loadReplay(lastEventID uint) error {
+	events, err := s.uc.eventRepo.FindAfterID(lastEventID, s.filter.WorkflowID, streamReplayLimit+1)
+	if err != nil {
+		return err
+	}
+
+	truncated := len(events) > streamReplayLimit
+	if truncated {
+		events = events[:streamReplayLimit]
+	}
+
+	for _, event := range events {
+		s.replayed[event.ID] = true
+		message := RequestEventMessage{
+			EventID:    event.ID,
+			Type:       event.Type,
+			OccurredAt: event.CreatedAt,
+			RequestID:  event.RequestID,
+			ActorID:    event.ActorID,
+			Before:     event.Before,
+			After:      event.After,
+		}
+
+		matches, err := s.matches(&message)
+		if err != nil {
+			return err
+		}
+		if matches {
+			s.replay = append(s.replay, message)
+		}
+	}
+
+	if truncated {
+		latestID, err := s.uc.eventRepo.LatestID()
+		if err != nil {
+			return err
+		}
+		s.skipThrough = latestID
+		s.replay = append(s.replay, RequestEventMessage{
+			EventID:    latestID,
+			Type:       StreamEventReset,
+			OccurredAt: time.Now(),
+			WorkflowID: s.filter.WorkflowID,
+		})
+	}
+
+	return nil
+}
+
+func (s *requestStream) Next(timeout time.Duration) (RequestEventMessage, bool, error) {
+	if len(s.replay) > 0 {
+		message := s.replay[0]
+		s.replay = s.replay[1:]
+		return message, true, nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.lagged:
+			return RequestEventMessage{}, false, ErrStreamLagged
+		case <-timer.C:
+			return RequestEventMessage{}, false, nil
+		case event := <-s.live:
+			var message RequestEventMessage
+			if err := json.Unmarshal(event.Payload, &message); err != nil {
+				return RequestEventMessage{}, false, err
+			}
+			if s.replayed[message.EventID] || message.EventID <= s.skipThrough {
+				continue
+			}
+
+			matches, err := s.matches(&message)
+			if err != nil {
+				return RequestEventMessage{}, false, err
+			}
+			if matches {
+				return message, true, nil
+			}
+		}
+	}
+}
+
+func (s *requestStream) Close() {
+	s.unsubscribe()
+}
+
+// matches reports whether the event passes the filter and concerns a request
+// the viewer may see. It fills in the workflow of the request.
+func (s *requestStream) matches(message *RequestEventMessage) (bool, error) {
+	if s.filter.WorkflowID != 0 && message.WorkflowID != 0 && message.WorkflowID != s.filter.WorkflowID {
+		return false, nil
+	}
+
+	if s.filter.Status != "" {
+		var after requestSnapshot
+		if len(message.After) == 0 || json.Unmarshal(message.After, &after) != nil || after.Status != s.filter.Status {
+			return false, nil
+		}
+	}
+
+	audience, err := s.uc.audiences.get(message.EventID, func() (streamAudience, error) {
+		return s.uc.resolveStreamAudience(message.RequestID)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	message.WorkflowID = audience.Request.WorkflowID
+	if s.filter.WorkflowID != 0 && audience.Request.WorkflowID != s.filter.WorkflowID {
+		return false, nil
+	}
+
+	if s.filter.AssignedToMe {
+		return audience.assignedTo(s.viewer, s.principals), nil
+	}
+	return audience.visibleTo(s.viewer, s.principals), nil
+}
+
+// streamAudience is what decides who may see the events of a request: the
+// request itself, the actor of the step it waits on and the users who
+// decided on it. It is resolved once per event and shared by every stream.
+type streamAudience struct {
+	Request   model.Request
+	StepActor *actorPrincipal // nil unless the request is pending
+	Deciders  map[uint]bool
+}
+
+func (uc *requestUsecase) resolveStreamAudience(requestID uint) (streamAudience, error) {
+	request, err := uc.requestRepo.FindByID(int(requestID))
+	if err != nil {
+		return streamAudience{}, err
+	}
+	audience := streamAudience{Request: request, Deciders: map[uint]bool{}}
+
+	tx := uc.requestRepo.BeginTransaction()
+	defer tx.Rollback()
+
+	deciderIDs, err := uc.approvalRepo.FindDeciderIDsTx(tx, request.ID)
+	if err != nil {
+		return streamAudience{}, err
+	}
+	for _, id := range deciderIDs {
+		audience.Deciders[id] = true
+	}
+
+	if request.Status == "PENDING" {
+		step, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, request.CurrentStep, int(request.WorkflowID))
+		if err != nil {
+			return streamAudience{}, err
+		}
+		principal, err := parseActor(step.Actor)
+		if err != nil {
+			return streamAudience{}, err
+		}
+		audience.StepActor = &principal
+	}
+
+	return audience, nil
+}
+
+// assignedTo reports whether the request is pending on a step the viewer can
+// act on.
+func (a streamAudience) assignedTo(viewer Viewer, principals userPrincipals) bool {
+	return viewer.UserID != 0 && a.StepActor != nil && principals.matches(*a.StepActor)
+}
+
+// visibleTo applies the same rules as canViewTx: admins, the requester, users
+// who decided on the request and those who can act on its current step.
+func (a streamAudience) visibleTo(viewer Viewer, principals userPrincipals) bool {
+	if viewer.Admin {
+		return true
+	}
+	if viewer.UserID == 0 {
+		return false
+	}
+	return a.Request.RequesterID == viewer.UserID || a.Deciders[viewer.UserID] || a.assignedTo(viewer, principals)
+}
+
+// streamAudienceCacheSize bounds how many recent events keep their resolved
+// audience. Streams read an event at about the same time, so a small window
+// is enough for all of them to share one lookup.
+const streamAudienceCacheSize = 256
+
+type streamAudienceEntry struct {
+	done     chan struct{}
+	audience streamAudience
+	err      error
+}
+
+// streamAudienceCache resolves the audience of an event once, however many
+// streams ask for it. Concurrent callers wait for the first one's lookup.
+type streamAudienceCache struct {
+	mu      sync.Mutex
+	entries map[uint]*streamAudienceEntry
+	order   []uint
+}
+
+func newStreamAudienceCache() *streamAudienceCache {
+	return &streamAudienceCache{entries: map[uint]*streamAudienceEntry{}}
+}
+
+func (c *streamAudienceCache) get(eventID uint, resolve func() (streamAudience, error)) (streamAudience, error) {
+	c.mu.Lock()
+	entry, found := c.entries[eventID]
+	if !found {
+		entry = &streamAudienceEntry{done: make(chan struct{})}
+		c.entries[eventID] = entry
+		c.order = append(c.order, eventID)
+		if len(c.order) > streamAudienceCacheSize {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.mu.Unlock()
+
+	if found {
+		<-entry.done
+		return entry.audience, entry.err
+	}
+
+	entry.audience, entry.err = resolve()
+	if entry.err != nil {
+		// Let the next caller try again instead of caching the failure.
+		c.mu.Lock()
+		if c.entries[eventID] == entry {
+			delete(c.entries, eventID)
+		}
+		c.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.audience, entry.err
+}
@@ -3,129 +3,297 @@ package usecase
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 	"technical-test/src/model"
 	"technical-test/src/repository"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 type RequestUsecase interface {
-	CreateRequest(workflowID int, amount float64) (model.Request, error)
-	GetRequestByID(id int) (model.Request, error)
-	FindAllRequestsWithPagination(page, pageSize int, search, status string) ([]model.Request, int64, error)
-	ApproveRequest(id int) (model.Request, error)
-	RejectRequest(id int) (model.Request, error)
+	CreateRequest(workflowID int, amount decimal.Decimal, currency string, metadata datatypes.JSON, userID uint) (model.Request, error)
+	GetRequestByID(id int, viewer Viewer) (model.Request, error)
+	FindAllRequestsWithPagination(page, pageSize int, search, status string, mine bool, viewer Viewer) ([]model.Request, int64, error)
+	FindInboxWithPagination(page, pageSize int, userID uint) ([]model.Request, int64, error)
+	ApproveRequest(id int, version uint, userID uint, comment string) (model.Request, error)
+	RejectRequest(id int, version uint, userID uint, reason, comment string) (model.Request, error)
+	BulkApproveRequests(items []BulkItem, userID uint, comment string) ([]BulkResult, error)
+	BulkRejectRequests(items []BulkItem, userID uint, reason, comment string) ([]BulkResult, error)
+	CancelRequest(id int, version uint, userID uint) (model.Request, error)
+	ReturnRequest(id int, version uint, userID uint, target string, level uint, comment string) (model.Request, error)
+	ResubmitRequest(id int, version uint, userID uint, amount *decimal.Decimal, metadata datatypes.JSON) (model.Request, error)
+	FindApprovalsByRequestID(requestID int, viewer Viewer) ([]model.Approval, error)
+	FindHistoryByRequestID(requestID int, viewer Viewer) ([]model.RequestEvent, error)
+	OpenRequestStream(lastEventID uint, filter StreamFilter, viewer Viewer) (RequestStream, error)
 }
 
 type requestUsecase struct {
 	requestRepo  repository.RequestRepository
 	stepRepo     repository.StepRepository
 	workflowRepo repository.WorkflowRepository
+	approvalRepo repository.ApprovalRepository
+	eventRepo    repository.RequestEventRepository
+	rateRepo     repository.ExchangeRateRepository
+	outboxRepo   repository.OutboxRepository
+	events       EventSubscriber
+	actors       actorResolver
+	audiences    *streamAudienceCache
 }
 
 type stepConditions struct {
-	MinAmount    float64 `json:"min_amount"`
-	ApprovalType string  `json:"approval_type"`
+	MinAmount       decimal.Decimal `json:"min_amount"`
+	Currency        string          `json:"currency"`
+	ApprovalType    string          `json:"approval_type"`
+	Quorum          *quorumRule     `json:"quorum"`
+	AppliesWhen     string          `json:"applies_when"`
+	AutoApproveWhen string          `json:"auto_approve_when"`
+	ReasonRequired  bool            `json:"reason_required"`
 }
 
 var (
 	ErrInvalidAmount       = errors.New("amount must be greater than 0")
 	ErrInvalidRequestState = errors.New("request is not in pending state")
 	ErrAmountBelowMinimum  = errors.New("amount does not meet minimum requirement for this step")
+	ErrInvalidConditions   = errors.New("conditions must be a valid JSON object")
+	ErrInvalidMetadata     = errors.New("metadata must be a JSON object")
+	ErrNotRequester        = errors.New("only the requester can change this request")
+	ErrReasonRequired      = errors.New("a rejection reason is required for this step")
 )
 
-func NewRequestUsecase(requestRepo repository.RequestRepository, stepRepo repository.StepRepository, workflowRepo repository.WorkflowRepository) RequestUsecase {
+func NewRequestUsecase(requestRepo repository.RequestRepository, stepRepo repository.StepRepository, workflowRepo repository.WorkflowRepository, approvalRepo repository.ApprovalRepository, eventRepo repository.RequestEventRepository, rateRepo repository.ExchangeRateRepository, outboxRepo repository.OutboxRepository, events EventSubscriber, userRepo repository.UserRepository, groupRepo repository.GroupRepository) RequestUsecase {
 	return &requestUsecase{
 		requestRepo:  requestRepo,
 		stepRepo:     stepRepo,
 		workflowRepo: workflowRepo,
+		approvalRepo: approvalRepo,
+		eventRepo:    eventRepo,
+		rateRepo:     rateRepo,
+		outboxRepo:   outboxRepo,
+		events:       events,
+		actors:       actorResolver{userRepo: userRepo, groupRepo: groupRepo},
+		audiences:    newStreamAudienceCache(),
 	}
 }
 
-func (uc *requestUsecase) CreateRequest(workflowID int, amount float64) (model.Request, error) {
-	if amount <= 0 {
-		return model.Request{}, ErrInvalidAmount
+// createRequestAttempts bounds the retries of a submission that lost the race
+// for the pending slot of its workflow to a concurrent submission.
+const createRequestAttempts = 3
+
+// CreateRequest submits an amount to a workflow. In merge mode the amount is
+// merged into the PENDING request of the workflow when there is one; in
+// separate mode, or without a pending request, a new request is created.
+// Submissions to the same workflow are serialized by locking the workflow row,
+// and the unique pending_workflow_id index guarantees a single merge target
+// even if that lock is bypassed.
+func (uc *requestUsecase) CreateRequest(workflowID int, amount decimal.Decimal, currency string, metadata datatypes.JSON, userID uint) (model.Request, error) {
+	if err := validateAmount(amount); err != nil {
+		return model.Request{}, err
+	}
+
+	// An empty currency is resolved to the workflow currency once the workflow
+	// is loaded.
+	if currency != "" {
+		var err error
+		if currency, err = normalizeCurrency(currency, ""); err != nil {
+			return model.Request{}, err
+		}
+	}
+
+	if err := validateMetadata(metadata); err != nil {
+		return model.Request{}, err
 	}
 
-	_, err := uc.workflowRepo.FindByID(workflowID)
+	var request model.Request
+	var err error
+	for attempt := 0; attempt < createRequestAttempts; attempt++ {
+		request, err = uc.submitRequest(workflowID, amount, currency, metadata, userID)
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			break
+		}
+	}
+	return request, err
+}
+
+func (uc *requestUsecase) submitRequest(workflowID int, amount decimal.Decimal, currency string, metadata datatypes.JSON, userID uint) (model.Request, error) {
+	tx := uc.requestRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	workflow, err := uc.workflowRepo.FindByIDWithLock(tx, workflowID)
 	if err != nil {
+		tx.Rollback()
 		return model.Request{}, err
 	}
 
-	_, err = uc.stepRepo.FindByLevelAndWorkflowID(1, workflowID)
+	// Thresholds are written in the workflow currency, so the amount is
+	// converted at the rate valid on the submission date.
+	if currency == "" {
+		currency = workflow.Currency
+	}
+	baseAmount, rate, err := convertTx(tx, uc.rateRepo, amount, currency, workflow.Currency, time.Now())
 	if err != nil {
+		tx.Rollback()
 		return model.Request{}, err
 	}
 
-	existingRequest, err := uc.requestRepo.FindPendingByWorkflowID(workflowID)
-	if err == nil && existingRequest.ID != 0 {
-		existingRequest.Amount += amount
+	if _, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, 1, workflowID); err != nil {
+		tx.Rollback()
+		return model.Request{}, err
+	}
 
-		accumulatedMinAmount, err := uc.getAccumulatedMinAmount(workflowID, existingRequest.CurrentStep)
-		if err != nil {
-			return model.Request{}, err
-		}
+	// Separate workflows never merge: every submission is its own request.
+	if workflow.SubmissionMode != model.SubmissionModeSeparate {
+		existingRequest, err := uc.requestRepo.FindPendingByWorkflowIDWithLock(tx, workflowID)
+		if err == nil && existingRequest.ID != 0 {
+			before := snapshotOf(existingRequest)
+			existingRequest.BaseAmount = existingRequest.BaseAmount.Add(baseAmount)
+			if existingRequest.Currency == currency && existingRequest.ExchangeRate.Equal(rate) {
+				existingRequest.Amount = existingRequest.Amount.Add(amount)
+			} else {
+				// Amounts in different currencies, or in the same currency
+				// converted at different rates, only add up in the base
+				// currency, so the request is restated in it to keep
+				// amount x exchange_rate = base_amount.
+				existingRequest.Amount = existingRequest.BaseAmount
+				existingRequest.Currency = existingRequest.BaseCurrency
+				existingRequest.ExchangeRate = decimal.NewFromInt(1)
+			}
+			existingRequest.SubmissionMode = model.SubmissionModeMerge
+			merged := snapshotOf(existingRequest)
 
-		if existingRequest.Amount >= accumulatedMinAmount {
-			nextStep, err := uc.stepRepo.FindByLevelAndWorkflowID(existingRequest.CurrentStep+1, workflowID)
-			if err == nil && nextStep.ID != 0 {
-				existingRequest.CurrentStep += 1
-			} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := uc.settleCurrentStepTx(tx, &existingRequest); err != nil {
+				tx.Rollback()
+				return model.Request{}, err
+			}
+
+			if err := uc.requestRepo.UpdateTx(tx, &existingRequest); err != nil {
+				tx.Rollback()
+				return model.Request{}, err
+			}
+
+			if err := uc.recordEventTx(tx, existingRequest, model.RequestEventAmountMerged, userID, &before, &merged); err != nil {
+				tx.Rollback()
 				return model.Request{}, err
-			} else {
-				existingRequest.Status = "APPROVED"
 			}
-		}
 
-		if err := uc.requestRepo.Update(&existingRequest); err != nil {
+			if err := uc.recordProgressTx(tx, existingRequest, userID, merged, snapshotOf(existingRequest)); err != nil {
+				tx.Rollback()
+				return model.Request{}, err
+			}
+
+			if err := tx.Commit().Error; err != nil {
+				return model.Request{}, err
+			}
+			return existingRequest, nil
+		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
 			return model.Request{}, err
 		}
-		return existingRequest, nil
-	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return model.Request{}, err
 	}
 
 	request := model.Request{
-		WorkflowID:  uint(workflowID),
-		CurrentStep: 1,
-		Status:      "PENDING",
-		Amount:      amount,
+		WorkflowID:     uint(workflowID),
+		RequesterID:    userID,
+		CurrentStep:    1,
+		Status:         "PENDING",
+		Amount:         amount,
+		Currency:       currency,
+		BaseAmount:     baseAmount,
+		BaseCurrency:   workflow.Currency,
+		ExchangeRate:   rate,
+		Metadata:       metadata,
+		SubmissionMode: model.SubmissionModeSeparate,
 	}
+	if workflow.SubmissionMode != model.SubmissionModeSeparate {
+		pendingWorkflowID := uint(workflowID)
+		request.PendingWorkflowID = &pendingWorkflowID
+		request.SubmissionMode = model.SubmissionModeMerge
+	}
+	created := snapshotOf(request)
 
-	accumulatedMinAmount, err := uc.getAccumulatedMinAmount(workflowID, 1)
-	if err != nil {
+	if err := uc.settleCurrentStepTx(tx, &request); err != nil {
+		tx.Rollback()
 		return model.Request{}, err
 	}
 
-	if amount >= accumulatedMinAmount {
-		nextStep, err := uc.stepRepo.FindByLevelAndWorkflowID(2, workflowID)
-		if err == nil && nextStep.ID != 0 {
-			request.CurrentStep = 2
-		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.Request{}, err
-		} else {
-			request.Status = "APPROVED"
-		}
+	if err := uc.requestRepo.CreateTx(tx, &request); err != nil {
+		tx.Rollback()
+		return model.Request{}, err
 	}
 
-	if err := uc.requestRepo.Create(&request); err != nil {
+	if err := uc.recordEventTx(tx, request, model.RequestEventCreated, userID, nil, &created); err != nil {
+		tx.Rollback()
+		return model.Request{}, err
+	}
+
+	if err := uc.recordProgressTx(tx, request, userID, created, snapshotOf(request)); err != nil {
+		tx.Rollback()
+		return model.Request{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return model.Request{}, err
 	}
 
 	return request, nil
 }
 
-func (uc *requestUsecase) GetRequestByID(id int) (model.Request, error) {
-	return uc.requestRepo.FindByID(id)
+func (uc *requestUsecase) GetRequestByID(id int, viewer Viewer) (model.Request, error) {
+	return uc.findVisibleRequest(id, viewer)
 }
 
-func (uc *requestUsecase) FindAllRequestsWithPagination(page, pageSize int, search, status string) ([]model.Request, int64, error) {
+func (uc *requestUsecase) FindAllRequestsWithPagination(page, pageSize int, search, status string, mine bool, viewer Viewer) ([]model.Request, int64, error) {
 	offset := (page - 1) * pageSize
-	return uc.requestRepo.FindAllWithPagination(offset, pageSize, search, status)
+
+	filter := repository.RequestFilter{Search: search, Status: status}
+	if mine {
+		filter.RequesterID = viewer.UserID
+	} else if !viewer.Admin {
+		// Same rule as findVisibleRequest: submitted, decided on, or pending
+		// on a step the viewer can act on.
+		tx := uc.requestRepo.BeginTransaction()
+		principals, err := uc.actors.principalsTx(tx, viewer.UserID)
+		tx.Rollback()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		filter.VisibleTo = viewer.UserID
+		filter.VisibleActors = principals.actorKeys()
+	}
+
+	return uc.requestRepo.FindAllWithPagination(offset, pageSize, filter)
 }
 
-func (uc *requestUsecase) ApproveRequest(id int) (model.Request, error) {
+// FindInboxWithPagination lists the pending requests waiting on a decision
+// from the user at their current step, oldest first.
+func (uc *requestUsecase) FindInboxWithPagination(page, pageSize int, userID uint) ([]model.Request, int64, error) {
+	tx := uc.requestRepo.BeginTransaction()
+	principals, err := uc.actors.principalsTx(tx, userID)
+	tx.Rollback()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	return uc.requestRepo.FindInboxWithPagination(offset, pageSize, userID, principals.actorKeys())
+}
+
+func (uc *requestUsecase) ApproveRequest(id int, version uint, userID uint, comment string) (model.Request, error) {
+	request, _, err := uc.approveRequest(id, version, userID, comment)
+	return request, err
+}
+
+// approveRequest records the approval and reports whether it was recorded. An
+// API step whose amount threshold is not met yet leaves the request untouched
+// and reports false without an error.
+func (uc *requestUsecase) approveRequest(id int, version uint, userID uint, comment string) (model.Request, bool, error) {
 	var request model.Request
 
 	tx := uc.requestRepo.BeginTransaction()
@@ -138,45 +306,178 @@ func (uc *requestUsecase) ApproveRequest(id int) (model.Request, error) {
 	request, err := uc.requestRepo.FindByIDWithLock(tx, id)
 	if err != nil {
 		tx.Rollback()
-		return request, err
+		return request, false, err
+	}
+
+	if err := checkVersion(version, request.Version); err != nil {
+		tx.Rollback()
+		return request, false, err
 	}
 
 	if request.Status != "PENDING" {
 		tx.Rollback()
-		return request, ErrInvalidRequestState
+		return request, false, ErrInvalidRequestState
 	}
 
 	step, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, request.CurrentStep, int(request.WorkflowID))
 	if err != nil {
 		tx.Rollback()
-		return request, err
+		return request, false, err
+	}
+
+	if err := uc.checkActorTx(tx, step, userID); err != nil {
+		tx.Rollback()
+		return request, false, err
+	}
+
+	voted, err := uc.approvalRepo.ExistsTx(tx, request.ID, request.CurrentStep, userID)
+	if err != nil {
+		tx.Rollback()
+		return request, false, err
+	}
+	if voted {
+		tx.Rollback()
+		return request, false, ErrDuplicateApproval
 	}
 
 	conditions, err := parseConditions(step.Conditions)
 	if err != nil {
 		tx.Rollback()
-		return request, err
+		return request, false, err
 	}
 
 	if conditions.ApprovalType == "API" {
-		accumulatedMinAmount, err := uc.getAccumulatedMinAmountTx(tx, int(request.WorkflowID), request.CurrentStep)
+		accumulatedMinAmount, err := uc.getAccumulatedMinAmountTx(tx, int(request.WorkflowID), request.CurrentStep, request.BaseCurrency)
 		if err != nil {
 			tx.Rollback()
-			return request, err
+			return request, false, err
+		}
+
+		if request.BaseAmount.LessThan(accumulatedMinAmount) {
+			tx.Rollback()
+			return request, false, nil
+		}
+	}
+
+	approval := model.Approval{
+		RequestID: request.ID,
+		StepLevel: request.CurrentStep,
+		UserID:    userID,
+		Decision:  "APPROVED",
+		Comment:   comment,
+	}
+	if err := uc.approvalRepo.CreateTx(tx, &approval); err != nil {
+		tx.Rollback()
+		return request, false, err
+	}
+
+	// The request stays on this level until enough distinct approvers signed.
+	reached, err := uc.quorumReachedTx(tx, request, step, conditions.Quorum)
+	if err != nil {
+		tx.Rollback()
+		return request, false, err
+	}
+
+	if reached {
+		before := snapshotOf(request)
+
+		if err := uc.advanceStepTx(tx, &request); err != nil {
+			tx.Rollback()
+			return request, false, err
+		}
+
+		if err := uc.requestRepo.UpdateTx(tx, &request); err != nil {
+			tx.Rollback()
+			return request, false, err
 		}
 
-		if request.Amount < accumulatedMinAmount {
+		if err := uc.recordProgressTx(tx, request, userID, before, snapshotOf(request)); err != nil {
+			tx.Rollback()
+			return request, false, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return request, false, err
+	}
+
+	return request, true, nil
+}
+
+func (uc *requestUsecase) RejectRequest(id int, version uint, userID uint, reason, comment string) (model.Request, error) {
+	var request model.Request
+
+	tx := uc.requestRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
 			tx.Rollback()
-			return request, nil
 		}
+	}()
+
+	request, err := uc.requestRepo.FindByIDWithLock(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := checkVersion(version, request.Version); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if request.Status != "PENDING" {
+		tx.Rollback()
+		return request, ErrInvalidRequestState
+	}
+
+	step, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, request.CurrentStep, int(request.WorkflowID))
+	if err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := uc.checkActorTx(tx, step, userID); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	conditions, err := parseConditions(step.Conditions)
+	if err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if conditions.ReasonRequired && reason == "" {
+		tx.Rollback()
+		return request, ErrReasonRequired
+	}
+
+	approval := model.Approval{
+		RequestID: request.ID,
+		StepLevel: request.CurrentStep,
+		UserID:    userID,
+		Decision:  "REJECTED",
+		Comment:   comment,
+	}
+	if err := uc.approvalRepo.CreateTx(tx, &approval); err != nil {
+		tx.Rollback()
+		return request, err
 	}
 
-	request.Status = "APPROVED"
+	before := snapshotOf(request)
+	request.Status = "REJECTED"
+	request.RejectionReason = reason
 	if err := uc.requestRepo.UpdateTx(tx, &request); err != nil {
 		tx.Rollback()
 		return request, err
 	}
 
+	if err := uc.recordProgressTx(tx, request, userID, before, snapshotOf(request)); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return request, err
 	}
@@ -184,75 +485,162 @@ func (uc *requestUsecase) ApproveRequest(id int) (model.Request, error) {
 	return request, nil
 }
 
-func (uc *requestUsecase) RejectRequest(id int) (model.Request, error) {
-	request, err := uc.requestRepo.FindByID(id)
+// CancelRequest withdraws a pending request on behalf of its requester. A
+// cancelled request is final and no longer receives merged amounts.
+func (uc *requestUsecase) CancelRequest(id int, version uint, userID uint) (model.Request, error) {
+	var request model.Request
+
+	tx := uc.requestRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	request, err := uc.requestRepo.FindByIDWithLock(tx, id)
 	if err != nil {
+		tx.Rollback()
 		return request, err
 	}
 
+	if err := checkVersion(version, request.Version); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if request.RequesterID == 0 || request.RequesterID != userID {
+		tx.Rollback()
+		return request, ErrNotRequester
+	}
+
 	if request.Status != "PENDING" {
+		tx.Rollback()
 		return request, ErrInvalidRequestState
 	}
 
-	request.Status = "REJECTED"
-	if err := uc.requestRepo.Update(&request); err != nil {
+	before := snapshotOf(request)
+	request.Status = "CANCELLED"
+	if err := uc.requestRepo.UpdateTx(tx, &request); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := uc.recordProgressTx(tx, request, userID, before, snapshotOf(request)); err != nil {
+		tx.Rollback()
+		return request, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return request, err
 	}
 
 	return request, nil
 }
 
-func (uc *requestUsecase) getAccumulatedMinAmount(workflowID int, currentLevel uint) (float64, error) {
-	var total float64 = 0
+func (uc *requestUsecase) FindApprovalsByRequestID(requestID int, viewer Viewer) ([]model.Approval, error) {
+	if _, err := uc.findVisibleRequest(requestID, viewer); err != nil {
+		return nil, err
+	}
+	return uc.approvalRepo.FindByRequestID(requestID)
+}
 
-	for level := uint(1); level <= currentLevel; level++ {
-		step, err := uc.stepRepo.FindByLevelAndWorkflowID(level, workflowID)
-		if err != nil {
-			return 0, err
-		}
+func (uc *requestUsecase) FindHistoryByRequestID(requestID int, viewer Viewer) ([]model.RequestEvent, error) {
+	if _, err := uc.findVisibleRequest(requestID, viewer); err != nil {
+		return nil, err
+	}
+	return uc.eventRepo.FindByRequestID(requestID)
+}
+
+func (uc *requestUsecase) checkActorTx(tx *gorm.DB, step model.Step, userID uint) error {
+	allowed, err := uc.actors.canActTx(tx, step.Actor, userID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrActorForbidden
+	}
+	return nil
+}
 
-		minAmount, err := parseMinAmount(step.Conditions)
+// settleCurrentStepTx advances the request past its current step when the
+// accumulated amount already meets the step thresholds, or when the step
+// conditions skip or auto-approve it.
+func (uc *requestUsecase) settleCurrentStepTx(tx *gorm.DB, request *model.Request) error {
+	step, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, request.CurrentStep, int(request.WorkflowID))
+	if err != nil {
+		return err
+	}
+
+	outcome, err := evaluateStep(step, *request)
+	if err != nil {
+		return err
+	}
+
+	if outcome == stepOutcomeManual {
+		accumulatedMinAmount, err := uc.getAccumulatedMinAmountTx(tx, int(request.WorkflowID), request.CurrentStep, request.BaseCurrency)
 		if err != nil {
-			return 0, err
+			return err
 		}
 
-		total += minAmount
+		if request.BaseAmount.LessThan(accumulatedMinAmount) {
+			return nil
+		}
 	}
 
-	return total, nil
+	return uc.advanceStepTx(tx, request)
+}
+
+// advanceStepTx moves the request to the next level of its workflow, or marks
+// it APPROVED when the current level is the last one. Levels whose conditions
+// skip or auto-approve the request are passed straight through.
+func (uc *requestUsecase) advanceStepTx(tx *gorm.DB, request *model.Request) error {
+	for {
+		nextStep, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, request.CurrentStep+1, int(request.WorkflowID))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			request.Status = "APPROVED"
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		request.CurrentStep += 1
+
+		outcome, err := evaluateStep(nextStep, *request)
+		if err != nil {
+			return err
+		}
+		if outcome == stepOutcomeManual {
+			return nil
+		}
+	}
 }
 
-func (uc *requestUsecase) getAccumulatedMinAmountTx(tx *gorm.DB, workflowID int, currentLevel uint) (float64, error) {
-	var total float64 = 0
+// getAccumulatedMinAmountTx sums the min_amount thresholds of the levels up to
+// currentLevel, to be compared with the base amount of the request. Thresholds
+// written in another currency are refused rather than compared as plain
+// numbers.
+func (uc *requestUsecase) getAccumulatedMinAmountTx(tx *gorm.DB, workflowID int, currentLevel uint, currency string) (decimal.Decimal, error) {
+	total := decimal.Zero
 
 	for level := uint(1); level <= currentLevel; level++ {
 		step, err := uc.stepRepo.FindByLevelAndWorkflowIDTx(tx, level, workflowID)
 		if err != nil {
-			return 0, err
+			return total, err
 		}
 
-		minAmount, err := parseMinAmount(step.Conditions)
+		cond, err := parseConditions(step.Conditions)
 		if err != nil {
-			return 0, err
+			return total, err
 		}
 
-		total += minAmount
-	}
-
-	return total, nil
-}
+		if cond.Currency != "" && !strings.EqualFold(cond.Currency, currency) {
+			return total, ErrCurrencyMismatch
+		}
 
-func parseMinAmount(conditions datatypes.JSON) (float64, error) {
-	if len(conditions) == 0 {
-		return 0, nil
+		total = total.Add(cond.MinAmount)
 	}
 
-	var cond stepConditions
-	if err := json.Unmarshal(conditions, &cond); err != nil {
-		return 0, err
-	}
-
-	return cond.MinAmount, nil
+	return total, nil
 }
 
 func parseConditions(conditions datatypes.JSON) (stepConditions, error) {
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+
+	"gorm.io/gorm"
+)
+
+type RoleUsecase interface {
+	CreateRole(name string, createdBy uint) (model.Role, error)
+	FindAllRolesWithPagination(page, pageSize int, search string) ([]model.Role, int64, error)
+}
+
+type roleUsecase struct {
+	roleRepo repository.RoleRepository
+}
+
+var (
+	ErrRoleExists      = errors.New("role already exists")
+	ErrInvalidRoleName = errors.New("role name must not be empty or contain ':'")
+)
+
+func NewRoleUsecase(roleRepo repository.RoleRepository) RoleUsecase {
+	return &roleUsecase{
+		roleRepo: roleRepo,
+	}
+}
+
+func (uc *roleUsecase) CreateRole(name string, createdBy uint) (model.Role, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, ":") {
+		return model.Role{}, ErrInvalidRoleName
+	}
+
+	if _, err := resolveRole(uc.roleRepo, name); err == nil {
+		return model.Role{}, ErrRoleExists
+	} else if !errors.Is(err, ErrInvalidRole) {
+		return model.Role{}, err
+	}
+
+	role := model.Role{Name: name, CreatedBy: createdBy}
+	if err := uc.roleRepo.Create(&role); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return model.Role{}, ErrRoleExists
+		}
+		return model.Role{}, err
+	}
+
+	return role, nil
+}
+
+func (uc *roleUsecase) FindAllRolesWithPagination(page, pageSize int, search string) ([]model.Role, int64, error) {
+	offset := (page - 1) * pageSize
+	return uc.roleRepo.FindAllWithPagination(offset, pageSize, search)
+}
+
+// resolveRole returns the canonical name of a built-in or admin-created role,
+// or ErrInvalidRole when no such role exists.
+func resolveRole(roleRepo repository.RoleRepository, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	for _, role := range model.Roles {
+		if strings.EqualFold(role, name) {
+			return role, nil
+		}
+	}
+
+	role, err := roleRepo.FindByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrInvalidRole
+	}
+	if err != nil {
+		return "", err
+	}
+	return role.Name, nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"technical-test/src/expression"
+	"technical-test/src/model"
+
+	"gorm.io/datatypes"
+)
+
+// Step conditions may hold two expressions evaluated against the request:
+//
+//	applies_when       the step is skipped when this evaluates to false
+//	auto_approve_when  the step is approved without a decision when true
+//
+// Expressions can refer to the identifiers listed in expressionIdentifiers.
+const (
+	stepOutcomeManual       = ""
+	stepOutcomeSkipped      = "SKIPPED"
+	stepOutcomeAutoApproved = "AUTO_APPROVED"
+)
+
+var ErrInvalidExpression = errors.New("invalid condition expression")
+
+var expressionIdentifiers = []string{"amount", "metadata", "workflow_id", "current_step"}
+
+func compileExpression(field, source string) (*expression.Expression, error) {
+	expr, err := expression.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidExpression, field, err)
+	}
+
+	for _, name := range expr.Identifiers() {
+		if !slices.Contains(expressionIdentifiers, name) {
+			return nil, fmt.Errorf("%w: %s: unknown identifier %q", ErrInvalidExpression, field, name)
+		}
+	}
+
+	return expr, nil
+}
+
+func (c stepConditions) validateExpressions() error {
+	if c.AppliesWhen != "" {
+		if _, err := compileExpression("applies_when", c.AppliesWhen); err != nil {
+			return err
+		}
+	}
+	if c.AutoApproveWhen != "" {
+		if _, err := compileExpression("auto_approve_when", c.AutoApproveWhen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluateStep decides whether the request passes the step without a human
+// decision, either because the step does not apply or because it auto-approves.
+func evaluateStep(step model.Step, request model.Request) (string, error) {
+	conditions, err := parseConditions(step.Conditions)
+	if err != nil {
+		return stepOutcomeManual, err
+	}
+
+	if conditions.AppliesWhen == "" && conditions.AutoApproveWhen == "" {
+		return stepOutcomeManual, nil
+	}
+
+	env, err := requestEnv(request)
+	if err != nil {
+		return stepOutcomeManual, err
+	}
+
+	if conditions.AppliesWhen != "" {
+		applies, err := evalExpression("applies_when", conditions.AppliesWhen, env)
+		if err != nil {
+			return stepOutcomeManual, err
+		}
+		if !applies {
+			return stepOutcomeSkipped, nil
+		}
+	}
+
+	if conditions.AutoApproveWhen != "" {
+		approved, err := evalExpression("auto_approve_when", conditions.AutoApproveWhen, env)
+		if err != nil {
+			return stepOutcomeManual, err
+		}
+		if approved {
+			return stepOutcomeAutoApproved, nil
+		}
+	}
+
+	return stepOutcomeManual, nil
+}
+
+func evalExpression(field, source string, env map[string]interface{}) (bool, error) {
+	expr, err := compileExpression(field, source)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := expr.EvalBool(env)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s: %v", ErrInvalidExpression, field, err)
+	}
+	return result, nil
+}
+
+func requestEnv(request model.Request) (map[string]interface{}, error) {
+	metadata := map[string]interface{}{}
+	if len(request.Metadata) > 0 {
+		// UseNumber keeps metadata numbers exact for the decimal comparisons.
+		decoder := json.NewDecoder(bytes.NewReader(request.Metadata))
+		decoder.UseNumber()
+		if err := decoder.Decode(&metadata); err != nil {
+			return nil, ErrInvalidMetadata
+		}
+	}
+
+	return map[string]interface{}{
+		"amount":       request.BaseAmount,
+		"metadata":     metadata,
+		"workflow_id":  request.WorkflowID,
+		"current_step": request.CurrentStep,
+	}, nil
+}
+
+func validateMetadata(metadata datatypes.JSON) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(metadata, &fields); err != nil {
+		return ErrInvalidMetadata
+	}
+	return nil
+}
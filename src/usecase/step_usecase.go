@@ -1,53 +1,104 @@
 package usecase
 
 import (
+	"errors"
+	"strconv"
 	"technical-test/src/model"
 	"technical-test/src/repository"
 
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type StepUsecase interface {
-	CreateStep(workflowID int, actor string, conditions datatypes.JSON) (model.Step, error)
+	CreateStep(workflowID int, workflowVersion uint, actor string, conditions datatypes.JSON) (model.Step, error)
 	GetNextLevelForWorkflow(workflowID int) (uint, error)
 	FindStepsByWorkflowID(workflowID int) ([]model.Step, error)
 	FindStepsByWorkflowIDWithPagination(workflowID int, page, pageSize int, search string) ([]model.Step, int64, error)
 	FindStepByLevelAndWorkflowID(level uint, workflowID int) (model.Step, error)
 	GetStepByID(id int) (model.Step, error)
-	UpdateStep(id int, level uint, actor string, conditions datatypes.JSON) (model.Step, error)
+	UpdateStep(id int, version uint, level uint, actor string, conditions datatypes.JSON) (model.Step, error)
 }
 
 type stepUsecase struct {
 	stepRepo     repository.StepRepository
 	workflowRepo repository.WorkflowRepository
+	userRepo     repository.UserRepository
+	groupRepo    repository.GroupRepository
+	roleRepo     repository.RoleRepository
 }
 
-func NewStepUsecase(stepRepo repository.StepRepository, workflowRepo repository.WorkflowRepository) StepUsecase {
+func NewStepUsecase(stepRepo repository.StepRepository, workflowRepo repository.WorkflowRepository, userRepo repository.UserRepository, groupRepo repository.GroupRepository, roleRepo repository.RoleRepository) StepUsecase {
 	return &stepUsecase{
 		stepRepo:     stepRepo,
 		workflowRepo: workflowRepo,
+		userRepo:     userRepo,
+		groupRepo:    groupRepo,
+		roleRepo:     roleRepo,
 	}
 }
 
-func (uc *stepUsecase) CreateStep(workflowID int, actor string, conditions datatypes.JSON) (model.Step, error) {
-	workflow, err := uc.workflowRepo.FindByID(workflowID)
+// CreateStep appends a step to the workflow. The workflow row is locked while
+// the next level is picked, and its version is bumped so clients holding the
+// old workflow ETag see that the definition changed.
+func (uc *stepUsecase) CreateStep(workflowID int, workflowVersion uint, actor string, conditions datatypes.JSON) (model.Step, error) {
+	actor, err := uc.validateActor(actor)
 	if err != nil {
 		return model.Step{}, err
 	}
 
-	nextLevel, err := uc.GetNextLevelForWorkflow(int(workflow.ID))
+	tx := uc.workflowRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	workflow, err := uc.workflowRepo.FindByIDWithLock(tx, workflowID)
 	if err != nil {
+		tx.Rollback()
+		return model.Step{}, err
+	}
+
+	if err := checkVersion(workflowVersion, workflow.Version); err != nil {
+		tx.Rollback()
+		return model.Step{}, err
+	}
+
+	if err := validateConditions(conditions, workflow.Currency); err != nil {
+		tx.Rollback()
+		return model.Step{}, err
+	}
+
+	if err := uc.validateQuorumTx(tx, actor, conditions); err != nil {
+		tx.Rollback()
+		return model.Step{}, err
+	}
+
+	maxLevel, err := uc.stepRepo.GetMaxLevelTx(tx, workflowID)
+	if err != nil {
+		tx.Rollback()
 		return model.Step{}, err
 	}
 
 	step := model.Step{
-		WorkflowID: uint(workflowID),
-		Level:      nextLevel,
+		WorkflowID: workflow.ID,
+		Level:      maxLevel + 1,
 		Actor:      actor,
 		Conditions: conditions,
 	}
 
-	if err := uc.stepRepo.Create(&step); err != nil {
+	if err := uc.stepRepo.CreateTx(tx, &step); err != nil {
+		tx.Rollback()
+		return model.Step{}, err
+	}
+
+	if err := uc.workflowRepo.UpdateTx(tx, &workflow); err != nil {
+		tx.Rollback()
+		return model.Step{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return model.Step{}, err
 	}
 
@@ -79,19 +130,149 @@ func (uc *stepUsecase) GetStepByID(id int) (model.Step, error) {
 	return uc.stepRepo.FindByID(id)
 }
 
-func (uc *stepUsecase) UpdateStep(id int, level uint, actor string, conditions datatypes.JSON) (model.Step, error) {
+// UpdateStep replaces the level, actor and conditions of a step if it is still
+// at version. Like CreateStep, it locks the workflow and bumps its version, so
+// the workflow ETag changes whenever one of its steps does.
+func (uc *stepUsecase) UpdateStep(id int, version uint, level uint, actor string, conditions datatypes.JSON) (model.Step, error) {
 	step, err := uc.stepRepo.FindByID(id)
 	if err != nil {
 		return step, err
 	}
 
+	actor, err = uc.validateActor(actor)
+	if err != nil {
+		return step, err
+	}
+
+	tx := uc.workflowRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	workflow, err := uc.workflowRepo.FindByIDWithLock(tx, int(step.WorkflowID))
+	if err != nil {
+		tx.Rollback()
+		return step, err
+	}
+
+	step, err = uc.stepRepo.FindByIDTx(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return step, err
+	}
+
+	if err := checkVersion(version, step.Version); err != nil {
+		tx.Rollback()
+		return step, err
+	}
+
+	if err := validateConditions(conditions, workflow.Currency); err != nil {
+		tx.Rollback()
+		return step, err
+	}
+
+	if err := uc.validateQuorumTx(tx, actor, conditions); err != nil {
+		tx.Rollback()
+		return step, err
+	}
+
 	step.Level = level
 	step.Actor = actor
 	step.Conditions = conditions
 
-	if err := uc.stepRepo.Update(&step); err != nil {
+	if err := uc.stepRepo.UpdateTx(tx, &step); err != nil {
+		tx.Rollback()
+		if errors.Is(err, repository.ErrStaleVersion) {
+			return step, ErrVersionMismatch
+		}
+		return step, err
+	}
+
+	if err := uc.workflowRepo.UpdateTx(tx, &workflow); err != nil {
+		tx.Rollback()
+		return step, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return step, err
 	}
 
 	return step, nil
 }
+
+// validateActor makes sure the actor is well formed and that it points to a
+// user, group or role that exists, so every step can be assigned to someone.
+// It returns the canonical form of the actor to store on the step.
+func (uc *stepUsecase) validateActor(actor string) (string, error) {
+	principal, err := parseActor(actor)
+	if err != nil {
+		return "", err
+	}
+
+	switch principal.Kind {
+	case ActorKindUser:
+		id, _ := strconv.Atoi(principal.Value)
+		_, err = uc.userRepo.FindByID(id)
+	case ActorKindGroup:
+		var group model.Group
+		group, err = uc.groupRepo.FindByName(principal.Value)
+		principal.Value = group.Name
+	case ActorKindRole:
+		principal.Value, err = resolveRole(uc.roleRepo, principal.Value)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrInvalidRole) {
+		return "", ErrActorNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return principal.String(), nil
+}
+
+// validateQuorumTx checks the quorum rule against the users the actor
+// currently resolves to, so a step cannot be saved with a quorum nobody can
+// meet.
+func (uc *stepUsecase) validateQuorumTx(tx *gorm.DB, actor string, conditions datatypes.JSON) error {
+	cond, err := parseConditions(conditions)
+	if err != nil {
+		return ErrInvalidConditions
+	}
+	if cond.Quorum == nil {
+		return nil
+	}
+
+	eligibleIDs, err := actorResolver{userRepo: uc.userRepo, groupRepo: uc.groupRepo}.eligibleUserIDsTx(tx, actor)
+	if err != nil {
+		return err
+	}
+
+	return cond.Quorum.checkReachable(eligibleIDs)
+}
+
+// validateConditions checks the step conditions of a workflow whose amounts
+// are in currency. A min_amount threshold must be in the workflow currency.
+func validateConditions(conditions datatypes.JSON, currency string) error {
+	cond, err := parseConditions(conditions)
+	if err != nil || cond.MinAmount.IsNegative() {
+		return ErrInvalidConditions
+	}
+
+	if cond.Currency != "" {
+		code, err := normalizeCurrency(cond.Currency, "")
+		if err != nil {
+			return err
+		}
+		if code != currency {
+			return ErrCurrencyMismatch
+		}
+	}
+
+	if err := cond.Quorum.validate(); err != nil {
+		return err
+	}
+
+	return cond.validateExpressions()
+}
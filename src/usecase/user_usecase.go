@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+)
+
+type UserUsecase interface {
+	FindAllUsersWithPagination(page, pageSize int, search string) ([]model.User, int64, error)
+	GetUserByID(id int) (model.User, error)
+	UpdateUserRoles(id int, roles []string) (model.User, error)
+}
+
+type userUsecase struct {
+	userRepo repository.UserRepository
+	roleRepo repository.RoleRepository
+}
+
+var ErrInvalidRole = errors.New("role must be one of admin, workflow-designer, requester, approver or a role created by an admin")
+
+func NewUserUsecase(userRepo repository.UserRepository, roleRepo repository.RoleRepository) UserUsecase {
+	return &userUsecase{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+func (uc *userUsecase) FindAllUsersWithPagination(page, pageSize int, search string) ([]model.User, int64, error) {
+	offset := (page - 1) * pageSize
+	return uc.userRepo.FindAllWithPagination(offset, pageSize, search)
+}
+
+func (uc *userUsecase) GetUserByID(id int) (model.User, error) {
+	return uc.userRepo.FindByID(id)
+}
+
+func (uc *userUsecase) UpdateUserRoles(id int, roles []string) (model.User, error) {
+	user, err := uc.userRepo.FindByID(id)
+	if err != nil {
+		return user, err
+	}
+
+	unique := make([]string, 0, len(roles))
+	for _, name := range roles {
+		role, err := resolveRole(uc.roleRepo, name)
+		if err != nil {
+			return user, err
+		}
+		if !slices.Contains(unique, role) {
+			unique = append(unique, role)
+		}
+	}
+
+	if err := uc.userRepo.ReplaceRoles(user.ID, unique); err != nil {
+		return user, err
+	}
+
+	return uc.userRepo.FindByID(id)
+}
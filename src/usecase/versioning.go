@@ -0,0 +1,14 @@
+package usecase
+
+import "errors"
+
+var ErrVersionMismatch = errors.New("resource was modified, fetch it again and retry")
+
+// checkVersion compares the version the client last saw (its If-Match) with the
+// stored one. Internal callers pass 0 to skip the check.
+func checkVersion(expected, actual uint) error {
+	if expected != 0 && expected != actual {
+		return ErrVersionMismatch
+	}
+	return nil
+}
@@ -0,0 +1,409 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v3/log"
+)
+
+// Headers sent with every webhook call. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// A failed delivery is retried after 30s, 1m, 2m, 4m, ... (at most one hour
+// apart) and marked FAILED once webhookMaxAttempts calls have failed.
+const (
+	webhookMaxAttempts   = 8
+	webhookBaseBackoff   = 30 * time.Second
+	webhookMaxBackoff    = time.Hour
+	webhookTimeout       = 10 * time.Second
+	webhookDispatchBatch = 50
+)
+
+// webhookEventTypes are the request events a subscription can select;
+// defaultWebhookEvents are used when it selects none.
+var (
+	webhookEventTypes = []string{
+		model.RequestEventCreated,
+		model.RequestEventAmountMerged,
+		model.RequestEventStepAdvanced,
+		model.RequestEventApproved,
+		model.RequestEventRejected,
+		model.RequestEventCancelled,
+		model.RequestEventReturned,
+		model.RequestEventResubmitted,
+	}
+	defaultWebhookEvents = []string{
+		model.RequestEventCreated,
+		model.RequestEventStepAdvanced,
+		model.RequestEventApproved,
+		model.RequestEventRejected,
+		model.RequestEventCancelled,
+	}
+)
+
+type WebhookUsecase interface {
+	CreateSubscription(targetURL, secret string, workflowID *uint, events []string, userID uint) (model.WebhookSubscription, string, error)
+	FindSubscriptionsWithPagination(page, pageSize, workflowID int) ([]model.WebhookSubscription, int64, error)
+	GetSubscriptionByID(id int) (model.WebhookSubscription, error)
+	DeleteSubscription(id int) error
+	FindDeliveriesWithPagination(subscriptionID, page, pageSize int, status string) ([]model.WebhookDelivery, int64, error)
+	Redeliver(subscriptionID, deliveryID int) (model.WebhookDelivery, error)
+	DispatchDue(now time.Time) (int, error)
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type webhookUsecase struct {
+	webhookRepo  repository.WebhookRepository
+	workflowRepo repository.WorkflowRepository
+	client       *http.Client
+}
+
+var (
+	ErrInvalidWebhookURL   = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookEvent = errors.New("unknown webhook event type")
+)
+
+func NewWebhookUsecase(webhookRepo repository.WebhookRepository, workflowRepo repository.WorkflowRepository) WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepo:  webhookRepo,
+		workflowRepo: workflowRepo,
+		client:       &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// CreateSubscription registers a receiver for one workflow, or for every
+// workflow when workflowID is nil. A secret is generated when none is given;
+// it is returned here only and never exposed again.
+func (uc *webhookUsecase) CreateSubscription(targetURL, secret string, workflowID *uint, events []string, userID uint) (model.WebhookSubscription, string, error) {
+	if err := validateWebhookURL(targetURL); err != nil {
+		return model.WebhookSubscription{}, "", err
+	}
+
+	eventList, err := normalizeWebhookEvents(events)
+	if err != nil {
+		return model.WebhookSubscription{}, "", err
+	}
+
+	if workflowID != nil {
+		if _, err := uc.workflowRepo.FindByID(int(*workflowID)); err != nil {
+			return model.WebhookSubscription{}, "", err
+		}
+	}
+
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return model.WebhookSubscription{}, "", err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	subscription := model.WebhookSubscription{
+		WorkflowID: workflowID,
+		URL:        targetURL,
+		Secret:     secret,
+		Events:     eventList,
+		CreatedBy:  userID,
+	}
+	if err := uc.webhookRepo.CreateSubscription(&subscription); err != nil {
+		return model.WebhookSubscription{}, "", err
+	}
+
+	return subscription, secret, nil
+}
+
+func (uc *webhookUsecase) FindSubscriptionsWithPagination(page, pageSize, workflowID int) ([]model.WebhookSubscription, int64, error) {
+	offset := (page - 1) * pageSize
+	return uc.webhookRepo.FindSubscriptionsWithPagination(offset, pageSize, workflowID)
+}
+
+func (uc *webhookUsecase) GetSubscriptionByID(id int) (model.WebhookSubscription, error) {
+	return uc.webhookRepo.FindSubscriptionByID(id)
+}
+
+func (uc *webhookUsecase) DeleteSubscription(id int) error {
+	if _, err := uc.webhookRepo.FindSubscriptionByID(id); err != nil {
+		return err
+	}
+	return uc.webhookRepo.DeleteSubscription(id)
+}
+
+func (uc *webhookUsecase) FindDeliveriesWithPagination(subscriptionID, page, pageSize int, status string) ([]model.WebhookDelivery, int64, error) {
+	if _, err := uc.webhookRepo.FindSubscriptionByID(subscriptionID); err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	return uc.webhookRepo.FindDeliveriesWithPagination(offset, pageSize, subscriptionID, strings.ToUpper(status))
+}
+
+// Redeliver sends the payload of a logged delivery again right away, as a new
+// delivery so the original attempt stays in the log. When this call fails the
+// new delivery is retried like any other.
+func (uc *webhookUsecase) Redeliver(subscriptionID, deliveryID int) (model.WebhookDelivery, error) {
+	subscription, err := uc.webhookRepo.FindSubscriptionByID(subscriptionID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	original, err := uc.webhookRepo.FindDeliveryByID(subscriptionID, deliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	now := time.Now()
+	delivery := model.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		RequestID:      original.RequestID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         model.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+	}
+	if err := uc.webhookRepo.CreateDelivery(&delivery); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	err = uc.attempt(subscription, &delivery, now)
+	return delivery, err
+}
+
+// DispatchDue attempts every pending delivery due at now and returns how many
+// were attempted. A delivery that cannot be attempted, for instance because
+// its subscription cannot be loaded, has the failure recorded like a failed
+// call and does not hold up the others; the errors are returned together.
+func (uc *webhookUsecase) DispatchDue(now time.Time) (int, error) {
+	deliveries, err := uc.webhookRepo.FindDueDeliveries(now, webhookDispatchBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	subscriptions := map[uint]model.WebhookSubscription{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = uc.webhookRepo.FindSubscriptionByID(int(delivery.SubscriptionID))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+				if err := uc.record(delivery, 0, err, now); err != nil {
+					errs = append(errs, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+				}
+				continue
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		if err := uc.attempt(subscription, delivery, now); err != nil {
+			errs = append(errs, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+		}
+	}
+
+	return len(deliveries), errors.Join(errs...)
+}
+
+// Run dispatches due deliveries every interval until ctx is done.
+func (uc *webhookUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := uc.DispatchDue(now); err != nil {
+				log.Errorf("Failed to dispatch webhooks: %v", err)
+			}
+		}
+	}
+}
+
+// attempt sends the delivery once and records the outcome, scheduling the next
+// attempt when the call failed.
+func (uc *webhookUsecase) attempt(subscription model.WebhookSubscription, delivery *model.WebhookDelivery, now time.Time) error {
+	status, err := uc.send(subscription, *delivery, now)
+	return uc.record(delivery, status, err, now)
+}
+
+// record stores the outcome of an attempt: delivered when err is nil,
+// otherwise retried later until webhookMaxAttempts is reached.
+func (uc *webhookUsecase) record(delivery *model.WebhookDelivery, status int, err error, now time.Time) error {
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	if err == nil {
+		delivery.Status = model.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		if len(delivery.LastError) > 1000 {
+			delivery.LastError = delivery.LastError[:1000]
+		}
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = model.WebhookDeliveryFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			next := now.Add(retryBackoff(delivery.Attempts, webhookBaseBackoff, webhookMaxBackoff))
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	return uc.webhookRepo.UpdateDelivery(delivery)
+}
+
+func (uc *webhookUsecase) send(subscription model.WebhookSubscription, delivery model.WebhookDelivery, now time.Time) (int, error) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := uc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 a receiver recomputes to
+// verify the X-Webhook-Signature header of a call.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type webhookSink struct {
+	webhookRepo repository.WebhookRepository
+}
+
+// NewWebhookSink returns the outbox sink that queues a webhook delivery of
+// each event for every subscription of its workflow that selected its type.
+// The deliveries of one event are queued together or not at all.
+func NewWebhookSink(webhookRepo repository.WebhookRepository) OutboxSink {
+	return &webhookSink{webhookRepo: webhookRepo}
+}
+
+func (s *webhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *webhookSink) Publish(event model.OutboxEvent) error {
+	tx := s.webhookRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	subscriptions, err := s.webhookRepo.FindSubscriptionsForWorkflowTx(tx, event.WorkflowID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Deliveries are due as soon as the event happened.
+	due := event.CreatedAt
+	for _, subscription := range subscriptions {
+		if !webhookSelects(subscription, event.Type) {
+			continue
+		}
+
+		delivery := model.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			RequestID:      event.RequestID,
+			EventType:      event.Type,
+			Payload:        event.Payload,
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  &due,
+		}
+		if err := s.webhookRepo.CreateDeliveryTx(tx, &delivery); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func webhookSelects(subscription model.WebhookSubscription, eventType string) bool {
+	for _, selected := range strings.Split(subscription.Events, ",") {
+		if selected == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func validateWebhookURL(targetURL string) error {
+	parsed, err := url.Parse(targetURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+func normalizeWebhookEvents(events []string) (string, error) {
+	if len(events) == 0 {
+		return strings.Join(defaultWebhookEvents, ","), nil
+	}
+
+	selected := make([]string, 0, len(events))
+	seen := map[string]bool{}
+	for _, event := range events {
+		event = strings.ToUpper(strings.TrimSpace(event))
+		known := false
+		for _, eventType := range webhookEventTypes {
+			if event == eventType {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return "", fmt.Errorf("%w: %q", ErrInvalidWebhookEvent, event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			selected = append(selected, event)
+		}
+	}
+	return strings.Join(selected, ","), nil
+}
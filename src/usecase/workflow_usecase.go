@@ -9,7 +9,7 @@ import (
 )
 
 type WorkflowUsecase interface {
-	CreateWorkflow(name string) (model.Workflow, error)
+	CreateWorkflow(name, submissionMode, currency string) (model.Workflow, error)
 	FindAllWorkflows() ([]model.Workflow, error)
 	FindAllWorkflowsWithPagination(page, pageSize int, search string) ([]model.Workflow, int64, error)
 	GetWorkflowByID(id int) (model.Workflow, error)
@@ -19,7 +19,10 @@ type workflowUsecase struct {
 	workflowRepo repository.WorkflowRepository
 }
 
-var ErrWorkflowNameExists = errors.New("workflow name already exists")
+var (
+	ErrWorkflowNameExists    = errors.New("workflow name already exists")
+	ErrInvalidSubmissionMode = errors.New("submission mode must be merge or separate")
+)
 
 func NewWorkflowUsecase(workflowRepo repository.WorkflowRepository) WorkflowUsecase {
 	return &workflowUsecase{
@@ -27,7 +30,20 @@ func NewWorkflowUsecase(workflowRepo repository.WorkflowRepository) WorkflowUsec
 	}
 }
 
-func (uc *workflowUsecase) CreateWorkflow(name string) (model.Workflow, error) {
+func (uc *workflowUsecase) CreateWorkflow(name, submissionMode, currency string) (model.Workflow, error) {
+	switch submissionMode {
+	case "":
+		submissionMode = model.SubmissionModeMerge
+	case model.SubmissionModeMerge, model.SubmissionModeSeparate:
+	default:
+		return model.Workflow{}, ErrInvalidSubmissionMode
+	}
+
+	currency, err := normalizeCurrency(currency, DefaultCurrency)
+	if err != nil {
+		return model.Workflow{}, err
+	}
+
 	existing, err := uc.workflowRepo.FindByName(name)
 	if err == nil && existing.ID != 0 {
 		return model.Workflow{}, ErrWorkflowNameExists
@@ -35,7 +51,7 @@ func (uc *workflowUsecase) CreateWorkflow(name string) (model.Workflow, error) {
 		return model.Workflow{}, err
 	}
 
-	workflow := model.Workflow{Name: name}
+	workflow := model.Workflow{Name: name, SubmissionMode: submissionMode, Currency: currency}
 	if err := uc.workflowRepo.Create(&workflow); err != nil {
 		return model.Workflow{}, err
 	}
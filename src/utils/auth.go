@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// GetUserID returns the authenticated user ID stored by the JWT middleware.
+// JWT numeric claims are decoded as float64, so the value is converted here.
+func GetUserID(c fiber.Ctx) uint {
+	switch id := c.Locals("user_id").(type) {
+	case float64:
+		return uint(id)
+	case uint:
+		return id
+	case int:
+		return uint(id)
+	}
+	return 0
+}
+
+// GetRoles returns the roles stored by the JWT middleware.
+func GetRoles(c fiber.Ctx) []string {
+	roles, _ := c.Locals("roles").([]string)
+	return roles
+}
+
+// HasAnyRole reports whether the authenticated user holds one of the roles.
+func HasAnyRole(c fiber.Ctx, roles ...string) bool {
+	for _, role := range GetRoles(c) {
+		if slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthUsecaseTestSuite struct {
+	BaseTestSuite
+	authUsecase usecase.AuthUsecase
+	userUsecase usecase.UserUsecase
+}
+
+func (suite *AuthUsecaseTestSuite) SetupTest() {
+	err := suite.InitializeDB("auth_usecase")
+	suite.NoError(err)
+
+	userRepo := repository.NewUserRepository(suite.DB)
+	suite.authUsecase = usecase.NewAuthUsecase(userRepo, repository.NewRefreshTokenRepository(suite.DB))
+	suite.userUsecase = usecase.NewUserUsecase(userRepo, repository.NewRoleRepository(suite.DB))
+}
+
+func (suite *AuthUsecaseTestSuite) TestRegister_AssignsDefaultRoles() {
+	suite.DB.Exec("DELETE FROM user_roles")
+	suite.DB.Exec("DELETE FROM users")
+
+	first, err := suite.authUsecase.Register("First", "first@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []string{model.RoleRequester, model.RoleAdmin}, first.RoleNames())
+
+	second, err := suite.authUsecase.Register("Second", "second@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []string{model.RoleRequester}, second.RoleNames())
+}
+
+func (suite *AuthUsecaseTestSuite) TestLogin_IncludesRolesInToken() {
+	registered, err := suite.authUsecase.Register("Designer", "designer@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.userUsecase.UpdateUserRoles(int(registered.ID), []string{model.RoleWorkflowDesigner})
+	assert.NoError(suite.T(), err)
+
+	tokens, user, err := suite.authUsecase.Login("designer@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), registered.ID, user.ID)
+
+	_, claims, err := usecase.ParseToken(tokens.AccessToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []interface{}{model.RoleWorkflowDesigner}, claims["roles"])
+}
+
+func (suite *AuthUsecaseTestSuite) TestRefresh_RotatesToken() {
+	registered, err := suite.authUsecase.Register("Rotating", "rotating@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+
+	login, _, err := suite.authUsecase.Login("rotating@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), login.RefreshToken)
+	assert.WithinDuration(suite.T(), time.Now().AddDate(0, 0, 7), login.RefreshExpiresAt, time.Minute)
+
+	// Stored hashed, never in clear
+	var stored model.RefreshToken
+	suite.DB.Where("user_id = ?", registered.ID).First(&stored)
+	assert.NotEqual(suite.T(), login.RefreshToken, stored.TokenHash)
+	assert.Len(suite.T(), stored.TokenHash, 64)
+
+	// Role changes apply from the next refresh
+	_, err = suite.userUsecase.UpdateUserRoles(int(registered.ID), []string{model.RoleApprover})
+	assert.NoError(suite.T(), err)
+
+	refreshed, user, err := suite.authUsecase.Refresh(login.RefreshToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), registered.ID, user.ID)
+	assert.NotEqual(suite.T(), login.RefreshToken, refreshed.RefreshToken)
+
+	_, claims, err := usecase.ParseToken(refreshed.AccessToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []interface{}{model.RoleApprover}, claims["roles"])
+
+	again, _, err := suite.authUsecase.Refresh(refreshed.RefreshToken)
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), again.AccessToken)
+
+	_, _, err = suite.authUsecase.Refresh("not-a-refresh-token")
+	assert.Equal(suite.T(), usecase.ErrInvalidRefreshToken, err)
+}
+
+func (suite *AuthUsecaseTestSuite) TestRefresh_ReuseRevokesFamily() {
+	_, err := suite.authUsecase.Register("Reused", "reused@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+
+	stolen, _, err := suite.authUsecase.Login("reused@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+	other, _, err := suite.authUsecase.Login("reused@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+
+	latest, _, err := suite.authUsecase.Refresh(stolen.RefreshToken)
+	assert.NoError(suite.T(), err)
+
+	// Presenting the rotated token again revokes its family
+	_, _, err = suite.authUsecase.Refresh(stolen.RefreshToken)
+	assert.Equal(suite.T(), usecase.ErrRefreshTokenReused, err)
+
+	_, _, err = suite.authUsecase.Refresh(latest.RefreshToken)
+	assert.Equal(suite.T(), usecase.ErrInvalidRefreshToken, err)
+
+	// Tokens of another login are not affected
+	_, _, err = suite.authUsecase.Refresh(other.RefreshToken)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AuthUsecaseTestSuite) TestRefresh_Expired() {
+	_, err := suite.authUsecase.Register("Expired", "expired@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+
+	login, user, err := suite.authUsecase.Login("expired@example.com", "secret123")
+	assert.NoError(suite.T(), err)
+
+	suite.DB.Model(&model.RefreshToken{}).Where("user_id = ?", user.ID).Update("expires_at", time.Now().Add(-time.Minute))
+
+	_, _, err = suite.authUsecase.Refresh(login.RefreshToken)
+	assert.Equal(suite.T(), usecase.ErrInvalidRefreshToken, err)
+}
+
+func (suite *AuthUsecaseTestSuite) TestUpdateUserRoles_InvalidRole() {
+	user := suite.CreateTestUser(model.RoleRequester)
+
+	_, err := suite.userUsecase.UpdateUserRoles(int(user.ID), []string{"superuser"})
+	assert.Equal(suite.T(), usecase.ErrInvalidRole, err)
+
+	stored, err := suite.userUsecase.GetUserByID(int(user.ID))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{model.RoleRequester}, stored.RoleNames())
+}
+
+func (suite *AuthUsecaseTestSuite) TestUpdateUserRoles_ReplacesRoles() {
+	user := suite.CreateTestUser(model.RoleRequester)
+
+	updated, err := suite.userUsecase.UpdateUserRoles(int(user.ID), []string{model.RoleApprover, model.RoleApprover, model.RoleWorkflowDesigner})
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []string{model.RoleApprover, model.RoleWorkflowDesigner}, updated.RoleNames())
+}
+
+func (suite *AuthUsecaseTestSuite) TestUpdateUserRoles_CustomRole() {
+	user := suite.CreateTestUser(model.RoleRequester)
+	roleUsecase := usecase.NewRoleUsecase(repository.NewRoleRepository(suite.DB))
+	name := fmt.Sprintf("Controller %d", suite.TestCounter)
+
+	_, err := suite.userUsecase.UpdateUserRoles(int(user.ID), []string{name})
+	assert.Equal(suite.T(), usecase.ErrInvalidRole, err)
+
+	_, err = roleUsecase.CreateRole(name, 0)
+	assert.NoError(suite.T(), err)
+
+	_, err = roleUsecase.CreateRole(strings.ToUpper(name), 0)
+	assert.Equal(suite.T(), usecase.ErrRoleExists, err)
+
+	_, err = roleUsecase.CreateRole("Admin", 0)
+	assert.Equal(suite.T(), usecase.ErrRoleExists, err)
+
+	updated, err := suite.userUsecase.UpdateUserRoles(int(user.ID), []string{strings.ToLower(name), "Requester"})
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []string{name, model.RoleRequester}, updated.RoleNames())
+}
+
+func TestAuthUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthUsecaseTestSuite))
+}
+
+type AuthConcurrencyTestSuite struct {
+	BaseTestSuite
+	authUsecase usecase.AuthUsecase
+}
+
+func (suite *AuthConcurrencyTestSuite) SetupTest() {
+	suite.DB = nil
+	err := suite.InitializeFileDB(filepath.Join(suite.T().TempDir(), "auth.db"))
+	suite.NoError(err)
+
+	suite.authUsecase = usecase.NewAuthUsecase(repository.NewUserRepository(suite.DB), repository.NewRefreshTokenRepository(suite.DB))
+}
+
+func (suite *AuthConcurrencyTestSuite) TearDownTest() {
+	if sqlDB, err := suite.DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// Test concurrent first registrations make exactly one admin
+func (suite *AuthConcurrencyTestSuite) TestRegister_ConcurrentFirstUsers() {
+	const registrations = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, registrations)
+
+	for i := 0; i < registrations; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := suite.authUsecase.Register("First", fmt.Sprintf("first%d@example.com", i), "secret123")
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(suite.T(), err)
+	}
+
+	var admins int64
+	suite.DB.Model(&model.UserRole{}).Where("role = ?", model.RoleAdmin).Count(&admins)
+	assert.Equal(suite.T(), int64(1), admins)
+}
+
+func TestAuthConcurrencyTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthConcurrencyTestSuite))
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"strings"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExchangeRateUsecaseTestSuite struct {
+	BaseTestSuite
+	exchangeRateUsecase usecase.ExchangeRateUsecase
+}
+
+func (suite *ExchangeRateUsecaseTestSuite) SetupTest() {
+	err := suite.InitializeDB("exchange_rate_usecase")
+	suite.NoError(err)
+
+	suite.exchangeRateUsecase = usecase.NewExchangeRateUsecase(repository.NewExchangeRateRepository(suite.DB))
+}
+
+func (suite *ExchangeRateUsecaseTestSuite) TestCreateExchangeRate() {
+	date := time.Date(2025, 3, 1, 15, 30, 0, 0, time.UTC)
+
+	rate, err := suite.exchangeRateUsecase.CreateExchangeRate("sgd", "idr", decimal.RequireFromString("12000.5"), date)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "SGD", rate.FromCurrency)
+	assert.Equal(suite.T(), "IDR", rate.ToCurrency)
+	assert.Equal(suite.T(), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), rate.EffectiveDate)
+
+	_, err = suite.exchangeRateUsecase.CreateExchangeRate("SGD", "IDR", decimal.NewFromInt(12500), date)
+	assert.Equal(suite.T(), usecase.ErrExchangeRateExists, err)
+
+	_, err = suite.exchangeRateUsecase.CreateExchangeRate("IDR", "IDR", decimal.NewFromInt(1), date)
+	assert.Equal(suite.T(), usecase.ErrSameCurrency, err)
+
+	_, err = suite.exchangeRateUsecase.CreateExchangeRate("USD", "IDR", decimal.Zero, date)
+	assert.Equal(suite.T(), usecase.ErrInvalidRate, err)
+}
+
+func (suite *ExchangeRateUsecaseTestSuite) TestImportExchangeRates() {
+	file := "from_currency,to_currency,rate,effective_date\n" +
+		"USD,IDR,16000,2025-01-01\n" +
+		"SGD,IDR,12000.25,2025-01-01\n"
+
+	imported, err := suite.exchangeRateUsecase.ImportExchangeRates(strings.NewReader(file))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, imported)
+
+	// Importing again replaces the rate stored for the same pair and date.
+	imported, err = suite.exchangeRateUsecase.ImportExchangeRates(strings.NewReader("from_currency,to_currency,rate,effective_date\nUSD,IDR,16100,2025-01-01\n"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, imported)
+
+	rates, total, err := suite.exchangeRateUsecase.FindExchangeRatesWithPagination(1, 10, "usd", "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+	assert.Equal(suite.T(), "16100", rates[0].Rate.String())
+
+	// A bad row rejects the whole file.
+	_, err = suite.exchangeRateUsecase.ImportExchangeRates(strings.NewReader("from_currency,to_currency,rate,effective_date\nEUR,IDR,17000,2025-01-01\nEUR,IDR,abc,2025-01-02\n"))
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidRateFile)
+	assert.Contains(suite.T(), err.Error(), "line 3")
+
+	_, total, _ = suite.exchangeRateUsecase.FindExchangeRatesWithPagination(1, 10, "EUR", "")
+	assert.Equal(suite.T(), int64(0), total)
+}
+
+func TestExchangeRateUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ExchangeRateUsecaseTestSuite))
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"technical-test/src/middleware"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotencyUsecaseTestSuite struct {
+	BaseTestSuite
+	idempotencyUsecase usecase.IdempotencyUsecase
+}
+
+func (suite *IdempotencyUsecaseTestSuite) SetupTest() {
+	err := suite.InitializeDB("idempotency_usecase")
+	suite.NoError(err)
+
+	suite.idempotencyUsecase = usecase.NewIdempotencyUsecase(repository.NewIdempotencyRepository(suite.DB))
+}
+
+// Test Begin replays a completed key and refuses a different fingerprint
+func (suite *IdempotencyUsecaseTestSuite) TestBegin_ReplayAndMismatch() {
+	user := suite.CreateTestUser()
+
+	record, replay, err := suite.idempotencyUsecase.Begin(user.ID, "key-1", "fingerprint-a")
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), replay)
+
+	_, _, err = suite.idempotencyUsecase.Begin(user.ID, "key-1", "fingerprint-a")
+	assert.Equal(suite.T(), usecase.ErrIdempotencyKeyInProgress, err)
+
+	err = suite.idempotencyUsecase.Complete(record, 200, `"3"`, []byte(`{"status":"success"}`))
+	assert.NoError(suite.T(), err)
+
+	stored, replay, err := suite.idempotencyUsecase.Begin(user.ID, "key-1", "fingerprint-a")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), replay)
+	assert.Equal(suite.T(), 200, stored.StatusCode)
+	assert.Equal(suite.T(), `"3"`, stored.ETag)
+	assert.JSONEq(suite.T(), `{"status":"success"}`, string(stored.Response))
+
+	_, _, err = suite.idempotencyUsecase.Begin(user.ID, "key-1", "fingerprint-b")
+	assert.Equal(suite.T(), usecase.ErrIdempotencyKeyReused, err)
+
+	// Keys are scoped per user.
+	other := suite.CreateTestUser()
+	_, replay, err = suite.idempotencyUsecase.Begin(other.ID, "key-1", "fingerprint-b")
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), replay)
+}
+
+// Test Release lets a failed call be retried with the same key
+func (suite *IdempotencyUsecaseTestSuite) TestRelease() {
+	user := suite.CreateTestUser()
+
+	record, _, err := suite.idempotencyUsecase.Begin(user.ID, "key-2", "fingerprint-a")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.idempotencyUsecase.Release(record))
+
+	_, replay, err := suite.idempotencyUsecase.Begin(user.ID, "key-2", "fingerprint-a")
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), replay)
+}
+
+// Test the middleware runs the handler once per key and replays its response
+func (suite *IdempotencyUsecaseTestSuite) TestIdempotencyMiddleware() {
+	user := suite.CreateTestUser()
+	calls := 0
+
+	app := fiber.New()
+	app.Post("/requests", func(c fiber.Ctx) error {
+		c.Locals("user_id", float64(user.ID))
+		return c.Next()
+	}, middleware.Idempotency(suite.idempotencyUsecase), func(c fiber.Ctx) error {
+		calls++
+		return c.JSON(fiber.Map{"calls": calls})
+	})
+
+	send := func(key, body string) (int, string, string) {
+		req := httptest.NewRequest("POST", "/requests", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		resp, err := app.Test(req)
+		suite.Require().NoError(err)
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data), resp.Header.Get("Idempotent-Replayed")
+	}
+
+	status, body, replayed := send("retry-1", `{"amount": 100}`)
+	assert.Equal(suite.T(), 200, status)
+	assert.JSONEq(suite.T(), `{"calls": 1}`, body)
+	assert.Empty(suite.T(), replayed)
+
+	status, body, replayed = send("retry-1", `{"amount": 100}`)
+	assert.Equal(suite.T(), 200, status)
+	assert.JSONEq(suite.T(), `{"calls": 1}`, body)
+	assert.Equal(suite.T(), "true", replayed)
+
+	status, _, _ = send("retry-1", `{"amount": 200}`)
+	assert.Equal(suite.T(), fiber.StatusConflict, status)
+
+	_, body, _ = send("", `{"amount": 100}`)
+	assert.JSONEq(suite.T(), `{"calls": 2}`, body)
+	assert.Equal(suite.T(), 2, calls)
+}
+
+// Test If-Match and the query are part of the fingerprint and the ETag is replayed
+func (suite *IdempotencyUsecaseTestSuite) TestIdempotencyMiddleware_IfMatchAndETag() {
+	user := suite.CreateTestUser()
+	calls := 0
+
+	app := fiber.New()
+	app.Post("/requests/1/approve", func(c fiber.Ctx) error {
+		c.Locals("user_id", float64(user.ID))
+		return c.Next()
+	}, middleware.Idempotency(suite.idempotencyUsecase), func(c fiber.Ctx) error {
+		calls++
+		c.Set(fiber.HeaderETag, fmt.Sprintf(`"%d"`, calls+1))
+		return c.JSON(fiber.Map{"calls": calls})
+	})
+
+	send := func(target, ifMatch string) (int, string) {
+		req := httptest.NewRequest("POST", target, strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "approve-1")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		resp, err := app.Test(req)
+		suite.Require().NoError(err)
+		return resp.StatusCode, resp.Header.Get("ETag")
+	}
+
+	status, etag := send("/requests/1/approve", `"1"`)
+	assert.Equal(suite.T(), 200, status)
+	assert.Equal(suite.T(), `"2"`, etag)
+
+	status, etag = send("/requests/1/approve", `"1"`)
+	assert.Equal(suite.T(), 200, status)
+	assert.Equal(suite.T(), `"2"`, etag)
+
+	status, _ = send("/requests/1/approve", `"2"`)
+	assert.Equal(suite.T(), fiber.StatusConflict, status)
+
+	status, _ = send("/requests/1/approve?dry_run=true", `"1"`)
+	assert.Equal(suite.T(), fiber.StatusConflict, status)
+	assert.Equal(suite.T(), 1, calls)
+}
+
+func TestIdempotencyUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyUsecaseTestSuite))
+}
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"technical-test/src/mailer"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationTestSuite struct {
+	BaseTestSuite
+	requestUsecase usecase.RequestUsecase
+	outboxRepo     repository.OutboxRepository
+	mailer         *mailer.MemoryMailer
+	outbox         usecase.OutboxDispatcher
+}
+
+func (suite *NotificationTestSuite) SetupTest() {
+	err := suite.InitializeDB("notification")
+	suite.NoError(err)
+
+	suite.requestUsecase, _, _ = suite.CreateRequestUsecaseWithDeps()
+	suite.outboxRepo = repository.NewOutboxRepository(suite.DB)
+	suite.mailer = mailer.NewMemoryMailer()
+	suite.outbox = usecase.NewOutboxDispatcher(suite.outboxRepo, usecase.NewNotificationSink(
+		suite.mailer,
+		repository.NewNotificationTemplateRepository(suite.DB),
+		repository.NewRequestRepository(suite.DB),
+		repository.NewStepRepository(suite.DB),
+		repository.NewWorkflowRepository(suite.DB),
+		repository.NewApprovalRepository(suite.DB),
+		repository.NewUserRepository(suite.DB),
+		repository.NewGroupRepository(suite.DB),
+	))
+
+	// Publish the events of earlier tests so each test only sees its own mail
+	_, err = suite.outbox.DispatchPending(time.Now())
+	suite.NoError(err)
+}
+
+// createWorkflow creates a workflow whose first step belongs to a group of two
+// reviewers and whose second step belongs to a single user.
+func (suite *NotificationTestSuite) createWorkflow() (model.Workflow, []model.User, model.User) {
+	reviewers := []model.User{suite.CreateTestUser(), suite.CreateTestUser()}
+	group := suite.CreateTestGroup(reviewers...)
+	director := suite.CreateTestUser()
+
+	workflow := suite.CreateTestWorkflowWithSteps("Notification Workflow", "group:"+group.Name, fmt.Sprintf("user:%d", director.ID))
+	return workflow, reviewers, director
+}
+
+func (suite *NotificationTestSuite) dispatch() []mailer.Message {
+	sent := len(suite.mailer.Messages())
+	_, err := suite.outbox.DispatchPending(time.Now())
+	suite.NoError(err)
+	return suite.mailer.Messages()[sent:]
+}
+
+// Test approvers are told when a request reaches their step and the requester
+// when it is approved
+func (suite *NotificationTestSuite) TestNotifications_ApprovalFlow() {
+	workflow, reviewers, director := suite.createWorkflow()
+	requester := suite.CreateTestUser("Requester")
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+
+	messages := suite.dispatch()
+	assert.Len(suite.T(), messages, 2)
+	assert.ElementsMatch(suite.T(), []string{reviewers[0].Email, reviewers[1].Email}, []string{messages[0].To[0], messages[1].To[0]})
+	assert.Equal(suite.T(), fmt.Sprintf("Request #%d is waiting for your approval", request.ID), messages[0].Subject)
+	assert.Contains(suite.T(), messages[0].TextBody, "100 IDR")
+
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, reviewers[0].ID, "")
+	assert.NoError(suite.T(), err)
+
+	messages = suite.dispatch()
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), []string{director.Email}, messages[0].To)
+	assert.Contains(suite.T(), messages[0].TextBody, "at step 2")
+
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, director.ID, "")
+	assert.NoError(suite.T(), err)
+
+	messages = suite.dispatch()
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), []string{requester.Email}, messages[0].To)
+	assert.Equal(suite.T(), fmt.Sprintf("Request #%d was approved", request.ID), messages[0].Subject)
+}
+
+// Test the requester is told about rejections and returns with the comment
+func (suite *NotificationTestSuite) TestNotifications_RejectAndReturn() {
+	workflow, reviewers, _ := suite.createWorkflow()
+	requester := suite.CreateTestUser("Requester")
+
+	returned, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	suite.dispatch()
+
+	_, err = suite.requestUsecase.ReturnRequest(int(returned.ID), 0, reviewers[1].ID, usecase.ReturnTargetRequester, 0, "Attach the invoice")
+	assert.NoError(suite.T(), err)
+
+	messages := suite.dispatch()
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), []string{requester.Email}, messages[0].To)
+	assert.Equal(suite.T(), fmt.Sprintf("Request #%d was returned for revision", returned.ID), messages[0].Subject)
+	assert.Contains(suite.T(), messages[0].TextBody, "Comment: Attach the invoice")
+	assert.Contains(suite.T(), messages[0].TextBody, "by "+reviewers[1].Name)
+
+	// Resubmitting puts the request back on the reviewers' step
+	_, err = suite.requestUsecase.ResubmitRequest(int(returned.ID), 0, requester.ID, nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), suite.dispatch(), 2)
+
+	_, err = suite.requestUsecase.RejectRequest(int(returned.ID), 0, reviewers[0].ID, "Over budget", "Try next quarter")
+	assert.NoError(suite.T(), err)
+
+	messages = suite.dispatch()
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), fmt.Sprintf("Request #%d was rejected", returned.ID), messages[0].Subject)
+	assert.Contains(suite.T(), messages[0].TextBody, "Reason: Over budget")
+	assert.Contains(suite.T(), messages[0].TextBody, "Comment: Try next quarter")
+}
+
+// Test a failing mailer neither breaks the decision nor loses the mail
+func (suite *NotificationTestSuite) TestNotifications_MailerFailure() {
+	workflow, reviewers, _ := suite.createWorkflow()
+	requester := suite.CreateTestUser("Requester")
+
+	suite.mailer.Err = errors.New("connection refused")
+	defer func() { suite.mailer.Err = nil }()
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+
+	now := time.Now()
+	_, err = suite.outbox.DispatchPending(now)
+	assert.NoError(suite.T(), err)
+
+	var event model.OutboxEvent
+	suite.DB.Where("request_id = ?", request.ID).First(&event)
+	assert.Nil(suite.T(), event.DeliveredAt)
+	assert.Contains(suite.T(), event.LastError, fmt.Sprintf("email: user %d: connection refused", reviewers[0].ID))
+
+	// The reviewers can still decide while the mail server is down
+	approved, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, reviewers[0].ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(2), approved.CurrentStep)
+
+	suite.mailer.Err = nil
+	_, err = suite.outbox.DispatchPending(now.Add(time.Minute))
+	assert.NoError(suite.T(), err)
+
+	suite.DB.Where("request_id = ?", request.ID).First(&event)
+	assert.NotNil(suite.T(), event.DeliveredAt)
+}
+
+// Test an approver whose mail fails does not make the others get it again
+func (suite *NotificationTestSuite) TestNotifications_PartialFailure() {
+	workflow, reviewers, _ := suite.createWorkflow()
+	requester := suite.CreateTestUser("Requester")
+
+	suite.mailer.FailTo = map[string]error{reviewers[1].Email: errors.New("mailbox unavailable")}
+	defer func() { suite.mailer.FailTo = nil }()
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+
+	messages := suite.dispatch()
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), []string{reviewers[0].Email}, messages[0].To)
+
+	var event model.OutboxEvent
+	suite.DB.Where("request_id = ?", request.ID).First(&event)
+	assert.NotNil(suite.T(), event.DeliveredAt)
+
+	suite.mailer.FailTo = nil
+	_, err = suite.outbox.DispatchPending(time.Now().Add(time.Minute))
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.dispatch())
+}
+
+// Test an approver who cannot be notified at all does not stop the event from
+// being retried for one whose mail failed
+func (suite *NotificationTestSuite) TestNotifications_PermanentAndTransientFailure() {
+	workflow, reviewers, _ := suite.createWorkflow()
+	requester := suite.CreateTestUser("Requester")
+
+	// Stored directly, as validation would refuse it
+	suite.DB.Create(&model.NotificationTemplate{
+		WorkflowID: &workflow.ID,
+		Kind:       usecase.NotificationStepAssigned,
+		Subject:    fmt.Sprintf(`{{if eq .Recipient.Email %q}}{{.Request.Missing}}{{end}}Assigned`, reviewers[0].Email),
+		TextBody:   "Hello",
+	})
+	suite.mailer.FailTo = map[string]error{reviewers[1].Email: errors.New("mailbox unavailable")}
+	defer func() { suite.mailer.FailTo = nil }()
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.dispatch())
+
+	var event model.OutboxEvent
+	suite.DB.Where("request_id = ?", request.ID).First(&event)
+	assert.Nil(suite.T(), event.DeliveredAt)
+	assert.Contains(suite.T(), event.LastError, fmt.Sprintf("user %d: mailbox unavailable", reviewers[1].ID))
+
+	suite.mailer.FailTo = nil
+	sent := len(suite.mailer.Messages())
+	_, err = suite.outbox.DispatchPending(time.Now().Add(time.Minute))
+	assert.NoError(suite.T(), err)
+
+	messages := suite.mailer.Messages()[sent:]
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), []string{reviewers[1].Email}, messages[0].To)
+
+	suite.DB.Where("request_id = ?", request.ID).First(&event)
+	assert.NotNil(suite.T(), event.DeliveredAt)
+}
+
+// Test a template that cannot be rendered does not keep the event pending
+func (suite *NotificationTestSuite) TestNotifications_BrokenTemplate() {
+	workflow, _, _ := suite.createWorkflow()
+	requester := suite.CreateTestUser("Requester")
+
+	// Stored directly, as validation would refuse it
+	suite.DB.Create(&model.NotificationTemplate{
+		WorkflowID: &workflow.ID,
+		Kind:       usecase.NotificationStepAssigned,
+		Subject:    "{{.Request.Missing}}",
+		TextBody:   "Hello",
+	})
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.dispatch())
+
+	var event model.OutboxEvent
+	suite.DB.Where("request_id = ?", request.ID).First(&event)
+	assert.NotNil(suite.T(), event.DeliveredAt)
+	assert.Empty(suite.T(), event.LastError)
+}
+
+func TestNotificationTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationTestSuite))
+}
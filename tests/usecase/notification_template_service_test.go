@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"fmt"
+	"html"
+	"technical-test/src/mailer"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationTemplateTestSuite struct {
+	BaseTestSuite
+	templateUsecase usecase.NotificationTemplateUsecase
+	requestUsecase  usecase.RequestUsecase
+	mailer          *mailer.MemoryMailer
+	outbox          usecase.OutboxDispatcher
+}
+
+func (suite *NotificationTemplateTestSuite) SetupTest() {
+	err := suite.InitializeDB("notification_template")
+	suite.NoError(err)
+
+	templateRepo := repository.NewNotificationTemplateRepository(suite.DB)
+	suite.templateUsecase = usecase.NewNotificationTemplateUsecase(templateRepo, repository.NewWorkflowRepository(suite.DB))
+	suite.requestUsecase, _, _ = suite.CreateRequestUsecaseWithDeps()
+	suite.mailer = mailer.NewMemoryMailer()
+	suite.outbox = usecase.NewOutboxDispatcher(repository.NewOutboxRepository(suite.DB), usecase.NewNotificationSink(
+		suite.mailer,
+		templateRepo,
+		repository.NewRequestRepository(suite.DB),
+		repository.NewStepRepository(suite.DB),
+		repository.NewWorkflowRepository(suite.DB),
+		repository.NewApprovalRepository(suite.DB),
+		repository.NewUserRepository(suite.DB),
+		repository.NewGroupRepository(suite.DB),
+	))
+
+	// Start every test from the built-in templates with an empty outbox
+	suite.DB.Where("1 = 1").Delete(&model.NotificationTemplate{})
+	_, err = suite.outbox.DispatchPending(time.Now())
+	suite.NoError(err)
+}
+
+// approve creates a request on the workflow, approves it and returns the email
+// sent to the requester.
+func (suite *NotificationTemplateTestSuite) approve(workflow model.Workflow, approver model.User) mailer.Message {
+	requester := suite.CreateTestUser("Requester")
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	suite.NoError(err)
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
+	suite.NoError(err)
+
+	sent := len(suite.mailer.Messages())
+	_, err = suite.outbox.DispatchPending(time.Now())
+	suite.NoError(err)
+
+	for _, message := range suite.mailer.Messages()[sent:] {
+		if message.To[0] == requester.Email {
+			return message
+		}
+	}
+	suite.Fail("no email sent to the requester")
+	return mailer.Message{}
+}
+
+// Test a workflow override wins over the global default, which wins over the
+// built-in text
+func (suite *NotificationTemplateTestSuite) TestNotificationTemplates_Resolution() {
+	hrApprover := suite.CreateTestUser("Approver")
+	hr := suite.CreateTestWorkflowWithSteps("HR Workflow", fmt.Sprintf("user:%d", hrApprover.ID))
+	procurementApprover := suite.CreateTestUser("Approver")
+	procurement := suite.CreateTestWorkflowWithSteps("Procurement Workflow", fmt.Sprintf("user:%d", procurementApprover.ID))
+	admin := suite.CreateTestUser("Admin")
+
+	message := suite.approve(hr, hrApprover)
+	assert.Contains(suite.T(), message.Subject, "was approved")
+	assert.Empty(suite.T(), message.HTMLBody)
+
+	_, err := suite.templateUsecase.CreateTemplate(usecase.NotificationApproved, nil,
+		"Permintaan #{{.Request.ID}} disetujui",
+		"Halo {{.Recipient.Name}}, permintaan Anda di {{.Workflow.Name}} disetujui.", "", admin.ID)
+	assert.NoError(suite.T(), err)
+
+	override, err := suite.templateUsecase.CreateTemplate("request_approved", &hr.ID,
+		"HR: request #{{.Request.ID}} approved",
+		"Approved by {{.Actor.Name}}",
+		"<p>Approved by <b>{{.Actor.Name}}</b></p>", admin.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), usecase.NotificationApproved, override.Kind)
+
+	message = suite.approve(hr, hrApprover)
+	assert.Contains(suite.T(), message.Subject, "HR: request #")
+	assert.Equal(suite.T(), "Approved by "+hrApprover.Name, message.TextBody)
+	assert.Equal(suite.T(), "<p>Approved by <b>"+hrApprover.Name+"</b></p>", message.HTMLBody)
+
+	message = suite.approve(procurement, procurementApprover)
+	assert.Contains(suite.T(), message.Subject, "disetujui")
+	assert.Contains(suite.T(), message.TextBody, procurement.Name)
+
+	// Without its override the workflow falls back to the global default
+	assert.ErrorIs(suite.T(), suite.templateUsecase.DeleteTemplate(int(override.ID), override.Version+1), usecase.ErrVersionMismatch)
+	assert.NoError(suite.T(), suite.templateUsecase.DeleteTemplate(int(override.ID), override.Version))
+	message = suite.approve(hr, hrApprover)
+	assert.Contains(suite.T(), message.Subject, "disetujui")
+
+	templates, total, err := suite.templateUsecase.FindTemplatesWithPagination(1, 10, "request_approved", 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+	assert.Nil(suite.T(), templates[0].WorkflowID)
+}
+
+// Test templates are validated when saved
+func (suite *NotificationTemplateTestSuite) TestNotificationTemplates_Validation() {
+	approver := suite.CreateTestUser("Approver")
+	workflow := suite.CreateTestWorkflowWithSteps("Validation Workflow", fmt.Sprintf("user:%d", approver.ID))
+	admin := suite.CreateTestUser("Admin")
+
+	_, err := suite.templateUsecase.CreateTemplate("REQUEST_ARCHIVED", nil, "Subject", "Body", "", admin.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationKind)
+
+	_, err = suite.templateUsecase.CreateTemplate(usecase.NotificationRejected, nil, "Request {{.Request.ID", "Body", "", admin.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+
+	_, err = suite.templateUsecase.CreateTemplate(usecase.NotificationRejected, nil, "Subject", "{{.Request.Title}}", "", admin.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+	assert.Contains(suite.T(), err.Error(), "text_body")
+
+	_, err = suite.templateUsecase.CreateTemplate(usecase.NotificationRejected, nil, "", "Body", "", admin.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+
+	template, err := suite.templateUsecase.CreateTemplate(usecase.NotificationRejected, &workflow.ID, "Rejected", "Body", "", admin.ID)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.templateUsecase.CreateTemplate(usecase.NotificationRejected, &workflow.ID, "Rejected again", "Body", "", admin.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrNotificationTemplateExists)
+
+	_, err = suite.templateUsecase.CreateTemplate(usecase.NotificationRejected, nil, "Rejected", "Body", "", admin.ID)
+	assert.NoError(suite.T(), err)
+	_, err = suite.templateUsecase.CreateTemplate(usecase.NotificationRejected, nil, "Rejected", "Body", "", admin.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrNotificationTemplateExists)
+
+	_, err = suite.templateUsecase.UpdateTemplate(int(template.ID), template.Version, "Rejected", "<p>{{.Missing}}</p>", "")
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+
+	// Users only expose their ID, name and email
+	_, err = suite.templateUsecase.UpdateTemplate(int(template.ID), template.Version, "Rejected", "{{.Actor.PasswordHash}}", "")
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+	_, err = suite.templateUsecase.UpdateTemplate(int(template.ID), template.Version, "Rejected", "Body", "<p>{{.Recipient.PasswordHash}}</p>")
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+
+	// Templates that only render when optional fields are set are refused too
+	_, err = suite.templateUsecase.UpdateTemplate(int(template.ID), template.Version, "Rejected by {{slice .Actor.Name 0 1}}", "Body", "")
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+	_, err = suite.templateUsecase.UpdateTemplate(int(template.ID), template.Version, "Rejected", "{{index .Request.Metadata 0}}", "")
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+
+	updated, err := suite.templateUsecase.UpdateTemplate(int(template.ID), template.Version, "Rejected: {{.Request.RejectionReason}}", "Body{{with .Actor.Name}} by {{slice . 0 1}}{{end}}", "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Rejected: {{.Request.RejectionReason}}", updated.Subject)
+	assert.Equal(suite.T(), &workflow.ID, updated.WorkflowID)
+	assert.Equal(suite.T(), template.Version+1, updated.Version)
+
+	// An update based on the old version is refused
+	_, err = suite.templateUsecase.UpdateTemplate(int(template.ID), template.Version, "Rejected", "Body", "")
+	assert.ErrorIs(suite.T(), err, usecase.ErrVersionMismatch)
+}
+
+// Test previews render against a sample request without storing anything
+func (suite *NotificationTemplateTestSuite) TestNotificationTemplates_Preview() {
+	approver := suite.CreateTestUser("Approver")
+	workflow := suite.CreateTestWorkflowWithSteps("R&D Preview Workflow", fmt.Sprintf("user:%d", approver.ID))
+
+	message, err := suite.templateUsecase.PreviewTemplate(usecase.NotificationReturned, &workflow.ID,
+		"Returned: {{.Workflow.Name}}",
+		"{{.Request.Amount}} {{.Request.Currency}} - {{.Comment}}",
+		`<p title="{{.Comment}}">{{.Workflow.Name}}: {{.Actor.Name}}</p>`)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Returned: "+workflow.Name, message.Subject)
+	assert.Equal(suite.T(), "1500000 IDR - Please attach the invoice", message.TextBody)
+	assert.Equal(suite.T(), `<p title="Please attach the invoice">`+html.EscapeString(workflow.Name)+`: John Smith</p>`, message.HTMLBody)
+	assert.NotEmpty(suite.T(), message.To)
+
+	// Without a template the built-in text is rendered
+	message, err = suite.templateUsecase.PreviewTemplate("step_assigned", nil, "", "", "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Request #1024 is waiting for your approval", message.Subject)
+	assert.Contains(suite.T(), message.TextBody, "Purchase Order")
+
+	_, err = suite.templateUsecase.PreviewTemplate(usecase.NotificationReturned, nil, "Subject", "{{.Request.Nope}}", "")
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidNotificationTemplate)
+
+	var count int64
+	suite.DB.Model(&model.NotificationTemplate{}).Count(&count)
+	assert.Zero(suite.T(), count)
+}
+
+func TestNotificationTemplateTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationTemplateTestSuite))
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutboxTestSuite struct {
+	BaseTestSuite
+	requestUsecase usecase.RequestUsecase
+	outboxRepo     repository.OutboxRepository
+}
+
+func (suite *OutboxTestSuite) SetupTest() {
+	err := suite.InitializeDB("outbox")
+	suite.NoError(err)
+
+	suite.requestUsecase, _, _ = suite.CreateRequestUsecaseWithDeps()
+	suite.outboxRepo = repository.NewOutboxRepository(suite.DB)
+}
+
+// recordingSink keeps the events it accepted and refuses the first failures
+// calls.
+type recordingSink struct {
+	name     string
+	failures int
+	events   []model.OutboxEvent
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Publish(event model.OutboxEvent) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (suite *OutboxTestSuite) createPendingRequest() (model.Request, model.User) {
+	approver := suite.CreateTestUser("Manager")
+	workflow := suite.CreateTestWorkflowWithSteps("Outbox Workflow", "Manager")
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, approver.ID)
+	suite.NoError(err)
+	return request, approver
+}
+
+func (suite *OutboxTestSuite) outboxEventsOf(requestID uint) []model.OutboxEvent {
+	var events []model.OutboxEvent
+	suite.DB.Where("request_id = ?", requestID).Order("id ASC").Find(&events)
+	return events
+}
+
+// Test events are written with the change and only for committed transactions
+func (suite *OutboxTestSuite) TestOutbox_WrittenInTransaction() {
+	request, approver := suite.createPendingRequest()
+
+	events := suite.outboxEventsOf(request.ID)
+	assert.Len(suite.T(), events, 1)
+	assert.Equal(suite.T(), model.RequestEventCreated, events[0].Type)
+	assert.Equal(suite.T(), request.WorkflowID, events[0].WorkflowID)
+
+	var message usecase.RequestEventMessage
+	assert.NoError(suite.T(), json.Unmarshal(events[0].Payload, &message))
+	assert.Equal(suite.T(), request.ID, message.RequestID)
+	assert.Equal(suite.T(), approver.ID, *message.ActorID)
+
+	// The approval of a user who is not the step actor is rolled back
+	outsider := suite.CreateTestUser("Director")
+	_, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, outsider.ID, "")
+	assert.Error(suite.T(), err)
+	assert.Len(suite.T(), suite.outboxEventsOf(request.ID), 1)
+
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
+	assert.NoError(suite.T(), err)
+
+	events = suite.outboxEventsOf(request.ID)
+	assert.Len(suite.T(), events, 2)
+	assert.Equal(suite.T(), model.RequestEventApproved, events[1].Type)
+}
+
+// Test a refused event is retried only for the sinks that refused it
+func (suite *OutboxTestSuite) TestDispatchPending_RetriesFailedSink() {
+	request, _ := suite.createPendingRequest()
+
+	healthy := &recordingSink{name: "healthy"}
+	flaky := &recordingSink{name: "flaky", failures: 1}
+	dispatcher := usecase.NewOutboxDispatcher(suite.outboxRepo, healthy, flaky)
+
+	now := time.Now()
+	_, err := dispatcher.DispatchPending(now)
+	assert.NoError(suite.T(), err)
+
+	event := suite.outboxEventsOf(request.ID)[0]
+	assert.Nil(suite.T(), event.DeliveredAt)
+	assert.Equal(suite.T(), uint(1), event.Attempts)
+	assert.Equal(suite.T(), "healthy", event.PublishedTo)
+	assert.Contains(suite.T(), event.LastError, "flaky: sink unavailable")
+	assert.WithinDuration(suite.T(), now.Add(5*time.Second), event.NextAttemptAt, time.Second)
+
+	_, err = dispatcher.DispatchPending(now.Add(6 * time.Second))
+	assert.NoError(suite.T(), err)
+
+	event = suite.outboxEventsOf(request.ID)[0]
+	assert.NotNil(suite.T(), event.DeliveredAt)
+	assert.Equal(suite.T(), "healthy,flaky", event.PublishedTo)
+	assert.Empty(suite.T(), event.LastError)
+
+	published := 0
+	for _, e := range healthy.events {
+		if e.ID == event.ID {
+			published++
+		}
+	}
+	assert.Equal(suite.T(), 1, published)
+
+	// Delivered events are not published again
+	count := len(flaky.events)
+	_, err = dispatcher.DispatchPending(now.Add(time.Hour))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), flaky.events, count)
+}
+
+// Test pruning only removes events delivered before the cutoff
+func (suite *OutboxTestSuite) TestPruneDelivered() {
+	old, _ := suite.createPendingRequest()
+	recent, _ := suite.createPendingRequest()
+	pending, _ := suite.createPendingRequest()
+
+	now := time.Now()
+	delivered := now.Add(-10 * 24 * time.Hour)
+	suite.DB.Model(&model.OutboxEvent{}).Where("request_id = ?", old.ID).Update("delivered_at", delivered)
+	suite.DB.Model(&model.OutboxEvent{}).Where("request_id = ?", recent.ID).Update("delivered_at", now)
+
+	expired := int64(len(suite.outboxEventsOf(old.ID)))
+	assert.NotZero(suite.T(), expired)
+
+	dispatcher := usecase.NewOutboxDispatcher(suite.outboxRepo)
+	removed, err := dispatcher.PruneDelivered(now.Add(-7 * 24 * time.Hour))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), expired, removed)
+
+	assert.Empty(suite.T(), suite.outboxEventsOf(old.ID))
+	assert.NotEmpty(suite.T(), suite.outboxEventsOf(recent.ID))
+	assert.NotEmpty(suite.T(), suite.outboxEventsOf(pending.ID))
+}
+
+// Test in-process subscribers receive published events until they unsubscribe
+func (suite *OutboxTestSuite) TestEventBus() {
+	bus := usecase.NewEventBus()
+	dispatcher := usecase.NewOutboxDispatcher(suite.outboxRepo, bus)
+
+	var received []model.OutboxEvent
+	unsubscribe := bus.Subscribe(func(event model.OutboxEvent) {
+		received = append(received, event)
+	})
+
+	request, approver := suite.createPendingRequest()
+	_, err := dispatcher.DispatchPending(time.Now())
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), received)
+	assert.Equal(suite.T(), request.ID, received[len(received)-1].RequestID)
+
+	unsubscribe()
+	count := len(received)
+
+	_, err = suite.requestUsecase.CancelRequest(int(request.ID), 0, approver.ID)
+	assert.NoError(suite.T(), err)
+	_, err = dispatcher.DispatchPending(time.Now())
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), received, count)
+}
+
+func TestOutboxTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxTestSuite))
+}
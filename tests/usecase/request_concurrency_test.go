@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"path/filepath"
+	"sync"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type RequestConcurrencyTestSuite struct {
+	BaseTestSuite
+	requestUsecase usecase.RequestUsecase
+}
+
+func (suite *RequestConcurrencyTestSuite) SetupTest() {
+	suite.DB = nil
+	err := suite.InitializeFileDB(filepath.Join(suite.T().TempDir(), "requests.db"))
+	suite.NoError(err)
+
+	suite.requestUsecase, _, _ = suite.CreateRequestUsecaseWithDeps()
+}
+
+func (suite *RequestConcurrencyTestSuite) TearDownTest() {
+	if sqlDB, err := suite.DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// Test concurrent CreateRequest calls merge into a single pending request
+// without losing any amount. SQLite runs the transactions one at a time, so
+// this checks the outcome; TestCreateRequest_SlotTaken covers the race.
+func (suite *RequestConcurrencyTestSuite) TestCreateRequest_ConcurrentMerge() {
+	workflow := suite.CreateTestWorkflow()
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000000, "approval_type": "MANUAL"}`)),
+	})
+
+	const submissions = 40
+	var wg sync.WaitGroup
+	errs := make(chan error, submissions)
+
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(10), "", nil, 0)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(suite.T(), err)
+	}
+
+	var requests []model.Request
+	suite.DB.Where("workflow_id = ?", workflow.ID).Find(&requests)
+	if assert.Len(suite.T(), requests, 1) {
+		assert.Equal(suite.T(), "PENDING", requests[0].Status)
+		assert.True(suite.T(), decimal.NewFromInt(submissions*10).Equal(requests[0].Amount))
+	}
+
+	var merged int64
+	suite.DB.Model(&model.RequestEvent{}).
+		Where("request_id = ? AND type = ?", requests[0].ID, model.RequestEventAmountMerged).
+		Count(&merged)
+	assert.Equal(suite.T(), int64(submissions-1), merged)
+}
+
+// staleRequestRepository misses the merge target on its first lookup, as a
+// submission does when another one takes the pending slot right after it
+// looked.
+type staleRequestRepository struct {
+	repository.RequestRepository
+	lookups int
+}
+
+func (r *staleRequestRepository) FindPendingByWorkflowIDWithLock(tx *gorm.DB, workflowID int) (model.Request, error) {
+	r.lookups++
+	if r.lookups == 1 {
+		return model.Request{}, gorm.ErrRecordNotFound
+	}
+	return r.RequestRepository.FindPendingByWorkflowIDWithLock(tx, workflowID)
+}
+
+// Test a submission that lost the pending slot to a concurrent one is retried
+// and merged into the request that holds the slot
+func (suite *RequestConcurrencyTestSuite) TestCreateRequest_SlotTaken() {
+	workflow := suite.CreateTestWorkflow()
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000000, "approval_type": "MANUAL"}`)),
+	})
+
+	holder := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(10), Currency: workflow.Currency, BaseAmount: decimal.NewFromInt(10), BaseCurrency: workflow.Currency, ExchangeRate: decimal.NewFromInt(1), SubmissionMode: model.SubmissionModeMerge, PendingWorkflowID: &workflow.ID}
+	assert.NoError(suite.T(), suite.DB.Create(&holder).Error)
+
+	requestRepo := &staleRequestRepository{RequestRepository: repository.NewRequestRepository(suite.DB)}
+	requestUsecase := usecase.NewRequestUsecase(
+		requestRepo,
+		repository.NewStepRepository(suite.DB),
+		repository.NewWorkflowRepository(suite.DB),
+		repository.NewApprovalRepository(suite.DB),
+		repository.NewRequestEventRepository(suite.DB),
+		repository.NewExchangeRateRepository(suite.DB),
+		repository.NewOutboxRepository(suite.DB),
+		usecase.NewEventBus(),
+		repository.NewUserRepository(suite.DB),
+		repository.NewGroupRepository(suite.DB),
+	)
+
+	request, err := requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(5), "", nil, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, requestRepo.lookups)
+	assert.Equal(suite.T(), holder.ID, request.ID)
+	assert.Equal(suite.T(), "15", request.Amount.String())
+
+	var pending int64
+	suite.DB.Model(&model.Request{}).Where("workflow_id = ? AND status = ?", workflow.ID, "PENDING").Count(&pending)
+	assert.Equal(suite.T(), int64(1), pending)
+}
+
+// Test the database refuses a second pending merge target for a workflow
+func (suite *RequestConcurrencyTestSuite) TestPendingWorkflowID_Unique() {
+	workflow := suite.CreateTestWorkflow()
+
+	first := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(10), BaseAmount: decimal.NewFromInt(10), PendingWorkflowID: &workflow.ID}
+	assert.NoError(suite.T(), suite.DB.Create(&first).Error)
+
+	second := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(10), BaseAmount: decimal.NewFromInt(10), PendingWorkflowID: &workflow.ID}
+	assert.ErrorIs(suite.T(), suite.DB.Create(&second).Error, gorm.ErrDuplicatedKey)
+
+	// Leaving PENDING releases the slot for a new merge target.
+	first.Status = "APPROVED"
+	assert.NoError(suite.T(), suite.DB.Save(&first).Error)
+	assert.Nil(suite.T(), first.PendingWorkflowID)
+	assert.NoError(suite.T(), suite.DB.Create(&second).Error)
+}
+
+// Test a write based on an outdated copy of a request is refused instead of
+// overwriting the newer row
+func (suite *RequestConcurrencyTestSuite) TestUpdate_StaleVersion() {
+	workflow := suite.CreateTestWorkflow()
+	request := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&request)
+
+	requestRepo := repository.NewRequestRepository(suite.DB)
+	first, _ := requestRepo.FindByID(int(request.ID))
+	second, _ := requestRepo.FindByID(int(request.ID))
+
+	first.Amount = decimal.NewFromInt(200)
+	assert.NoError(suite.T(), requestRepo.Update(&first))
+	assert.Equal(suite.T(), uint(2), first.Version)
+
+	second.Status = "CANCELLED"
+	assert.ErrorIs(suite.T(), requestRepo.Update(&second), repository.ErrStaleVersion)
+	assert.Equal(suite.T(), uint(1), second.Version)
+
+	stored, _ := requestRepo.FindByID(int(request.ID))
+	assert.Equal(suite.T(), "200", stored.Amount.String())
+	assert.Equal(suite.T(), "PENDING", stored.Status)
+}
+
+func TestRequestConcurrencyTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestConcurrencyTestSuite))
+}
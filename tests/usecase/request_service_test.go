@@ -1,15 +1,23 @@
 package usecase
 
 import (
+	"fmt"
+	"strings"
 	"technical-test/src/model"
+	"technical-test/src/repository"
 	"technical-test/src/usecase"
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
+var adminViewer = usecase.Viewer{Admin: true}
+
 type RequestUsecaseTestSuite struct {
 	BaseTestSuite
 	requestUsecase  usecase.RequestUsecase
@@ -38,11 +46,11 @@ func (suite *RequestUsecaseTestSuite) TestCreateRequest_ValidAmount() {
 	suite.DB.Create(&step)
 
 	// Create request with valid amount
-	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), 150)
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(150), "", nil, 0)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), workflow.ID, request.WorkflowID)
-	assert.Equal(suite.T(), 150.0, request.Amount)
+	assert.Equal(suite.T(), "150", request.Amount.String())
 	assert.Equal(suite.T(), "APPROVED", request.Status)
 }
 
@@ -51,7 +59,7 @@ func (suite *RequestUsecaseTestSuite) TestCreateRequest_InvalidAmount() {
 	workflow := suite.CreateTestWorkflow()
 
 	// Create request with invalid amount
-	_, err := suite.requestUsecase.CreateRequest(int(workflow.ID), -50)
+	_, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(-50), "", nil, 0)
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), usecase.ErrInvalidAmount, err)
@@ -61,7 +69,7 @@ func (suite *RequestUsecaseTestSuite) TestCreateRequest_InvalidAmount() {
 func (suite *RequestUsecaseTestSuite) TestCreateRequest_ZeroAmount() {
 	workflow := suite.CreateTestWorkflow()
 
-	_, err := suite.requestUsecase.CreateRequest(int(workflow.ID), 0)
+	_, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(0), "", nil, 0)
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), usecase.ErrInvalidAmount, err)
@@ -69,7 +77,7 @@ func (suite *RequestUsecaseTestSuite) TestCreateRequest_ZeroAmount() {
 
 // Test CreateRequest with non-existent workflow
 func (suite *RequestUsecaseTestSuite) TestCreateRequest_NonExistentWorkflow() {
-	_, err := suite.requestUsecase.CreateRequest(9999, 100)
+	_, err := suite.requestUsecase.CreateRequest(9999, decimal.NewFromInt(100), "", nil, 0)
 
 	assert.Error(suite.T(), err)
 }
@@ -88,12 +96,12 @@ func (suite *RequestUsecaseTestSuite) TestCreateRequest_BelowMinimum() {
 	suite.DB.Create(&step)
 
 	// Create request with amount below minimum
-	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), 50)
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(50), "", nil, 0)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), uint(1), request.CurrentStep)
 	assert.Equal(suite.T(), "PENDING", request.Status)
-	assert.Equal(suite.T(), 50.0, request.Amount)
+	assert.Equal(suite.T(), "50", request.Amount.String())
 }
 
 // Test CreateRequest with multi-level workflow
@@ -121,7 +129,7 @@ func (suite *RequestUsecaseTestSuite) TestCreateRequest_MultiLevelWorkflow() {
 	suite.DB.Create(&step2)
 
 	// Create request with amount meeting step 1 requirement but not step 2
-	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), 150)
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(150), "", nil, 0)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), uint(2), request.CurrentStep)
@@ -131,6 +139,7 @@ func (suite *RequestUsecaseTestSuite) TestCreateRequest_MultiLevelWorkflow() {
 // Test ApproveRequest with API approval type
 func (suite *RequestUsecaseTestSuite) TestApproveRequest_APIApprovalType() {
 	workflow := suite.CreateTestWorkflow()
+	approver := suite.CreateTestUser("Manager")
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100, "approval_type": "API"}`))
 	step := model.Step{
@@ -145,12 +154,13 @@ func (suite *RequestUsecaseTestSuite) TestApproveRequest_APIApprovalType() {
 		WorkflowID:  workflow.ID,
 		CurrentStep: 1,
 		Status:      "PENDING",
-		Amount:      150,
+		Amount:      decimal.NewFromInt(150),
+		BaseAmount:  decimal.NewFromInt(150),
 	}
 	suite.DB.Create(&request)
 
 	// Approve request
-	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID))
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "APPROVED", approvedRequest.Status)
@@ -159,6 +169,7 @@ func (suite *RequestUsecaseTestSuite) TestApproveRequest_APIApprovalType() {
 // Test ApproveRequest with MANUAL approval type
 func (suite *RequestUsecaseTestSuite) TestApproveRequest_ManualApprovalType() {
 	workflow := suite.CreateTestWorkflow()
+	approver := suite.CreateTestUser("Manager")
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100, "approval_type": "MANUAL"}`))
 	step := model.Step{
@@ -173,17 +184,61 @@ func (suite *RequestUsecaseTestSuite) TestApproveRequest_ManualApprovalType() {
 		WorkflowID:  workflow.ID,
 		CurrentStep: 1,
 		Status:      "PENDING",
-		Amount:      50,
+		Amount:      decimal.NewFromInt(50),
+		BaseAmount:  decimal.NewFromInt(50),
 	}
 	suite.DB.Create(&request)
 
 	// Approve request (should approve regardless of amount for MANUAL type)
-	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID))
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "APPROVED", approvedRequest.Status)
 }
 
+// Test ApproveRequest moves through every level before approving
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_MultiLevelProgression() {
+	workflow := suite.CreateTestWorkflow()
+	approver := suite.CreateTestUser("Manager", "Director", "CFO")
+
+	for level, actor := range []string{"Manager", "Director", "CFO"} {
+		step := model.Step{
+			WorkflowID: workflow.ID,
+			Level:      uint(level + 1),
+			Actor:      actor,
+			Conditions: datatypes.JSON([]byte(`{"min_amount": 100, "approval_type": "MANUAL"}`)),
+		}
+		suite.DB.Create(&step)
+	}
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(50),
+		BaseAmount:  decimal.NewFromInt(50),
+	}
+	suite.DB.Create(&request)
+
+	// First approval moves the request to level 2
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PENDING", approvedRequest.Status)
+	assert.Equal(suite.T(), uint(2), approvedRequest.CurrentStep)
+
+	// Second approval moves the request to level 3
+	approvedRequest, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PENDING", approvedRequest.Status)
+	assert.Equal(suite.T(), uint(3), approvedRequest.CurrentStep)
+
+	// Last level approves the request
+	approvedRequest, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "APPROVED", approvedRequest.Status)
+	assert.Equal(suite.T(), uint(3), approvedRequest.CurrentStep)
+}
+
 // Test ApproveRequest with invalid request state
 func (suite *RequestUsecaseTestSuite) TestApproveRequest_InvalidState() {
 	workflow := suite.CreateTestWorkflow()
@@ -192,12 +247,13 @@ func (suite *RequestUsecaseTestSuite) TestApproveRequest_InvalidState() {
 		WorkflowID:  workflow.ID,
 		CurrentStep: 1,
 		Status:      "APPROVED",
-		Amount:      100,
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
 	}
 	suite.DB.Create(&request)
 
 	// Try to approve already approved request
-	_, err := suite.requestUsecase.ApproveRequest(int(request.ID))
+	_, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, 1, "")
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), usecase.ErrInvalidRequestState, err)
@@ -206,17 +262,26 @@ func (suite *RequestUsecaseTestSuite) TestApproveRequest_InvalidState() {
 // Test RejectRequest
 func (suite *RequestUsecaseTestSuite) TestRejectRequest() {
 	workflow := suite.CreateTestWorkflow()
+	approver := suite.CreateTestUser("Manager")
+
+	step := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+	}
+	suite.DB.Create(&step)
 
 	request := model.Request{
 		WorkflowID:  workflow.ID,
 		CurrentStep: 1,
 		Status:      "PENDING",
-		Amount:      100,
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
 	}
 	suite.DB.Create(&request)
 
 	// Reject request
-	rejectedRequest, err := suite.requestUsecase.RejectRequest(int(request.ID))
+	rejectedRequest, err := suite.requestUsecase.RejectRequest(int(request.ID), 0, approver.ID, "", "")
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "REJECTED", rejectedRequest.Status)
@@ -230,17 +295,755 @@ func (suite *RequestUsecaseTestSuite) TestRejectRequest_InvalidState() {
 		WorkflowID:  workflow.ID,
 		CurrentStep: 1,
 		Status:      "REJECTED",
-		Amount:      100,
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
 	}
 	suite.DB.Create(&request)
 
 	// Try to reject already rejected request
-	_, err := suite.requestUsecase.RejectRequest(int(request.ID))
+	_, err := suite.requestUsecase.RejectRequest(int(request.ID), 0, 1, "", "")
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), usecase.ErrInvalidRequestState, err)
 }
 
+// Test ApproveRequest records who approved the step
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_RecordsApproval() {
+	workflow := suite.CreateTestWorkflow()
+	approver := suite.CreateTestUser("Manager")
+
+	step := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 100, "approval_type": "MANUAL"}`)),
+	}
+	suite.DB.Create(&step)
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(50),
+		BaseAmount:  decimal.NewFromInt(50),
+	}
+	suite.DB.Create(&request)
+
+	_, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "looks good")
+	assert.NoError(suite.T(), err)
+
+	approvals, err := suite.requestUsecase.FindApprovalsByRequestID(int(request.ID), adminViewer)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), approvals, 1)
+	assert.Equal(suite.T(), approver.ID, approvals[0].UserID)
+	assert.Equal(suite.T(), uint(1), approvals[0].StepLevel)
+	assert.Equal(suite.T(), "APPROVED", approvals[0].Decision)
+	assert.Equal(suite.T(), "looks good", approvals[0].Comment)
+	assert.NotNil(suite.T(), approvals[0].User)
+}
+
+// Test RejectRequest records who rejected the step
+func (suite *RequestUsecaseTestSuite) TestRejectRequest_RecordsApproval() {
+	workflow := suite.CreateTestWorkflow()
+	approver := suite.CreateTestUser("Manager")
+
+	step := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+	}
+	suite.DB.Create(&step)
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
+	}
+	suite.DB.Create(&request)
+
+	_, err := suite.requestUsecase.RejectRequest(int(request.ID), 0, approver.ID, "", "budget exceeded")
+	assert.NoError(suite.T(), err)
+
+	approvals, err := suite.requestUsecase.FindApprovalsByRequestID(int(request.ID), adminViewer)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), approvals, 1)
+	assert.Equal(suite.T(), approver.ID, approvals[0].UserID)
+	assert.Equal(suite.T(), "REJECTED", approvals[0].Decision)
+	assert.Equal(suite.T(), "budget exceeded", approvals[0].Comment)
+}
+
+// Test CancelRequest by the requester stops further merges into the request
+func (suite *RequestUsecaseTestSuite) TestCancelRequest() {
+	workflow := suite.CreateTestWorkflow()
+	requester := suite.CreateTestUser()
+	manager := suite.CreateTestUser("Manager")
+
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000, "approval_type": "MANUAL"}`)),
+	})
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.requestUsecase.CancelRequest(int(request.ID), 0, manager.ID)
+	assert.Equal(suite.T(), usecase.ErrNotRequester, err)
+
+	cancelledRequest, err := suite.requestUsecase.CancelRequest(int(request.ID), 0, requester.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "CANCELLED", cancelledRequest.Status)
+
+	_, err = suite.requestUsecase.CancelRequest(int(request.ID), 0, requester.ID)
+	assert.Equal(suite.T(), usecase.ErrInvalidRequestState, err)
+
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, manager.ID, "")
+	assert.Equal(suite.T(), usecase.ErrInvalidRequestState, err)
+
+	newRequest, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(200), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), request.ID, newRequest.ID)
+	assert.Equal(suite.T(), "200", newRequest.Amount.String())
+
+	events, err := suite.requestUsecase.FindHistoryByRequestID(int(request.ID), adminViewer)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.RequestEventCancelled, events[len(events)-1].Type)
+}
+
+// Test ReturnRequest to the requester and resubmission from the chosen level
+func (suite *RequestUsecaseTestSuite) TestReturnRequest_ToRequester() {
+	workflow := suite.CreateTestWorkflow()
+	requester := suite.CreateTestUser()
+	manager := suite.CreateTestUser("Manager")
+	director := suite.CreateTestUser("Director")
+
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000, "approval_type": "MANUAL"}`)),
+	})
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      2,
+		Actor:      "Director",
+		Conditions: datatypes.JSON([]byte(`{"approval_type": "MANUAL"}`)),
+	})
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(500), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+
+	request, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, manager.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(2), request.CurrentStep)
+
+	_, err = suite.requestUsecase.ReturnRequest(int(request.ID), 0, director.ID, usecase.ReturnTargetRequester, 3, "")
+	assert.Equal(suite.T(), usecase.ErrInvalidReturnTarget, err)
+
+	request, err = suite.requestUsecase.ReturnRequest(int(request.ID), 0, director.ID, usecase.ReturnTargetRequester, 0, "Please attach the invoice")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "RETURNED", request.Status)
+	assert.Equal(suite.T(), uint(1), request.ResumeLevel)
+
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, director.ID, "")
+	assert.Equal(suite.T(), usecase.ErrInvalidRequestState, err)
+
+	_, err = suite.requestUsecase.ResubmitRequest(int(request.ID), 0, manager.ID, nil, nil)
+	assert.Equal(suite.T(), usecase.ErrNotRequester, err)
+
+	amount := decimal.NewFromInt(1200)
+	request, err = suite.requestUsecase.ResubmitRequest(int(request.ID), 0, requester.ID, &amount, datatypes.JSON([]byte(`{"invoice": "INV-1"}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PENDING", request.Status)
+	assert.Equal(suite.T(), "1200", request.Amount.String())
+	// The new amount meets the level 1 threshold, so the request moves on to level 2.
+	assert.Equal(suite.T(), uint(2), request.CurrentStep)
+
+	_, err = suite.requestUsecase.ResubmitRequest(int(request.ID), 0, requester.ID, nil, nil)
+	assert.Equal(suite.T(), usecase.ErrRequestNotReturned, err)
+
+	request, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, director.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "APPROVED", request.Status)
+}
+
+// Test a resubmitted request takes the pending slot back, or goes on as a
+// separate request when a newer submission holds it
+func (suite *RequestUsecaseTestSuite) TestResubmitRequest_PendingSlot() {
+	workflow := suite.CreateTestWorkflow()
+	requester := suite.CreateTestUser()
+	manager := suite.CreateTestUser("Manager")
+
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000, "approval_type": "MANUAL"}`)),
+	})
+
+	first, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	_, err = suite.requestUsecase.ReturnRequest(int(first.ID), 0, manager.ID, usecase.ReturnTargetRequester, 0, "")
+	assert.NoError(suite.T(), err)
+
+	// Without a pending request the slot is free again: the first request
+	// takes it back and receives the next merge
+	first, err = suite.requestUsecase.ResubmitRequest(int(first.ID), 0, requester.ID, nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &workflow.ID, first.PendingWorkflowID)
+
+	merged, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(50), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), first.ID, merged.ID)
+
+	// A newer submission takes the slot while the first request is returned
+	_, err = suite.requestUsecase.ReturnRequest(int(first.ID), 0, manager.ID, usecase.ReturnTargetRequester, 0, "")
+	assert.NoError(suite.T(), err)
+	second, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(200), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), first.ID, second.ID)
+
+	first, err = suite.requestUsecase.ResubmitRequest(int(first.ID), 0, requester.ID, nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PENDING", first.Status)
+	assert.Nil(suite.T(), first.PendingWorkflowID)
+	assert.Equal(suite.T(), model.SubmissionModeSeparate, first.SubmissionMode)
+
+	// Later submissions keep merging into the slot holder
+	merged, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(300), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), second.ID, merged.ID)
+	assert.Equal(suite.T(), "500", merged.Amount.String())
+
+	var pending int64
+	suite.DB.Model(&model.Request{}).Where("pending_workflow_id = ?", workflow.ID).Count(&pending)
+	assert.Equal(suite.T(), int64(1), pending)
+}
+
+// Test ReturnRequest to an earlier level lets that level decide again
+func (suite *RequestUsecaseTestSuite) TestReturnRequest_ToLevel() {
+	workflow := suite.CreateTestWorkflow()
+	manager := suite.CreateTestUser("Manager")
+	director := suite.CreateTestUser("Director")
+
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 1, Actor: "Manager"})
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 2, Actor: "Director"})
+
+	request := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&request)
+
+	_, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, manager.ID, "")
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.requestUsecase.ReturnRequest(int(request.ID), 0, director.ID, usecase.ReturnTargetLevel, 2, "")
+	assert.Equal(suite.T(), usecase.ErrInvalidReturnTarget, err)
+
+	returned, err := suite.requestUsecase.ReturnRequest(int(request.ID), 0, director.ID, usecase.ReturnTargetLevel, 1, "Check the budget line")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PENDING", returned.Status)
+	assert.Equal(suite.T(), uint(1), returned.CurrentStep)
+
+	// The earlier approval was superseded, so the manager can approve again.
+	approved, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, manager.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(2), approved.CurrentStep)
+
+	approvals, err := suite.requestUsecase.FindApprovalsByRequestID(int(request.ID), adminViewer)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), approvals, 3)
+	assert.True(suite.T(), approvals[0].Superseded)
+	assert.Equal(suite.T(), "RETURNED", approvals[1].Decision)
+	assert.False(suite.T(), approvals[2].Superseded)
+}
+
+// Test RejectRequest on a step that requires a reason
+func (suite *RequestUsecaseTestSuite) TestRejectRequest_ReasonRequired() {
+	workflow := suite.CreateTestWorkflow()
+	approver := suite.CreateTestUser("Manager")
+
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"approval_type": "MANUAL", "reason_required": true}`)),
+	})
+
+	request := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&request)
+
+	_, err := suite.requestUsecase.RejectRequest(int(request.ID), 0, approver.ID, "  ", "")
+	assert.Equal(suite.T(), usecase.ErrReasonRequired, err)
+
+	rejectedRequest, err := suite.requestUsecase.RejectRequest(int(request.ID), 0, approver.ID, "Missing invoice", "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "REJECTED", rejectedRequest.Status)
+
+	fetchedRequest, err := suite.requestUsecase.GetRequestByID(int(request.ID), adminViewer)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Missing invoice", fetchedRequest.RejectionReason)
+}
+
+// Test FindApprovalsByRequestID with non-existent request
+func (suite *RequestUsecaseTestSuite) TestFindApprovalsByRequestID_NotFound() {
+	_, err := suite.requestUsecase.FindApprovalsByRequestID(9999, adminViewer)
+
+	assert.Error(suite.T(), err)
+}
+
+// Test ApproveRequest by a user who is not the step actor
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_ForbiddenActor() {
+	workflow := suite.CreateTestWorkflow()
+	outsider := suite.CreateTestUser("Director")
+
+	step := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "role:Manager",
+	}
+	suite.DB.Create(&step)
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
+	}
+	suite.DB.Create(&request)
+
+	_, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, outsider.ID, "")
+	assert.Equal(suite.T(), usecase.ErrActorForbidden, err)
+
+	_, err = suite.requestUsecase.RejectRequest(int(request.ID), 0, outsider.ID, "", "")
+	assert.Equal(suite.T(), usecase.ErrActorForbidden, err)
+
+	var stored model.Request
+	suite.DB.First(&stored, request.ID)
+	assert.Equal(suite.T(), "PENDING", stored.Status)
+}
+
+// Test ApproveRequest with user and group actors
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_UserAndGroupActors() {
+	workflow := suite.CreateTestWorkflow()
+	director := suite.CreateTestUser()
+	member := suite.CreateTestUser()
+	group := suite.CreateTestGroup(member)
+
+	step1 := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      fmt.Sprintf("user:%d", director.ID),
+	}
+	suite.DB.Create(&step1)
+
+	step2 := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      2,
+		Actor:      "group:" + group.Name,
+	}
+	suite.DB.Create(&step2)
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
+	}
+	suite.DB.Create(&request)
+
+	// Group member cannot act on the user step
+	_, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, member.ID, "")
+	assert.Equal(suite.T(), usecase.ErrActorForbidden, err)
+
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, director.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(2), approvedRequest.CurrentStep)
+
+	// Director is not part of the group
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, director.ID, "")
+	assert.Equal(suite.T(), usecase.ErrActorForbidden, err)
+
+	approvedRequest, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, member.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "APPROVED", approvedRequest.Status)
+}
+
+// Test ApproveRequest waits for N of M approvers and refuses duplicate votes
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_QuorumNOfM() {
+	workflow := suite.CreateTestWorkflow()
+	cfo1 := suite.CreateTestUser()
+	cfo2 := suite.CreateTestUser()
+	cfo3 := suite.CreateTestUser()
+	group := suite.CreateTestGroup(cfo1, cfo2, cfo3)
+
+	step := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "group:" + group.Name,
+		Conditions: datatypes.JSON([]byte(`{"quorum": {"rule": "n_of_m", "required": 2}}`)),
+	}
+	suite.DB.Create(&step)
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
+	}
+	suite.DB.Create(&request)
+
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, cfo1.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PENDING", approvedRequest.Status)
+
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, cfo1.ID, "")
+	assert.Equal(suite.T(), usecase.ErrDuplicateApproval, err)
+
+	approvedRequest, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, cfo3.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "APPROVED", approvedRequest.Status)
+
+	approvals, err := suite.requestUsecase.FindApprovalsByRequestID(int(request.ID), adminViewer)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), approvals, 2)
+}
+
+// Test ApproveRequest with a quorum that needs every member
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_QuorumAll() {
+	workflow := suite.CreateTestWorkflow()
+	legal1 := suite.CreateTestUser()
+	legal2 := suite.CreateTestUser()
+	group := suite.CreateTestGroup(legal1, legal2)
+
+	step := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "group:" + group.Name,
+		Conditions: datatypes.JSON([]byte(`{"quorum": {"rule": "all"}}`)),
+	}
+	suite.DB.Create(&step)
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
+	}
+	suite.DB.Create(&request)
+
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, legal2.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PENDING", approvedRequest.Status)
+
+	approvedRequest, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, legal1.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "APPROVED", approvedRequest.Status)
+}
+
+// Test ApproveRequest with a weighted quorum
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_QuorumWeighted() {
+	workflow := suite.CreateTestWorkflow()
+	cfo := suite.CreateTestUser("Finance")
+	analyst := suite.CreateTestUser("Finance")
+
+	step := model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Finance",
+		Conditions: datatypes.JSON([]byte(fmt.Sprintf(`{"quorum": {"rule": "n_of_m", "required": 3, "weights": {"%d": 2}}}`, cfo.ID))),
+	}
+	suite.DB.Create(&step)
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
+	}
+	suite.DB.Create(&request)
+
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, cfo.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PENDING", approvedRequest.Status)
+
+	approvedRequest, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, analyst.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "APPROVED", approvedRequest.Status)
+}
+
+// Test ApproveRequest refuses a decision based on an outdated version
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_StaleVersion() {
+	workflow := suite.CreateTestWorkflow()
+	manager := suite.CreateTestUser("Manager")
+
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 1, Actor: "Manager"})
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 2, Actor: "Manager"})
+
+	request := model.Request{
+		WorkflowID:  workflow.ID,
+		CurrentStep: 1,
+		Status:      "PENDING",
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
+	}
+	suite.DB.Create(&request)
+	assert.Equal(suite.T(), uint(1), request.Version)
+
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), request.Version, manager.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(2), approvedRequest.CurrentStep)
+	assert.Equal(suite.T(), uint(2), approvedRequest.Version)
+
+	_, err = suite.requestUsecase.RejectRequest(int(request.ID), request.Version, manager.ID, "", "")
+	assert.ErrorIs(suite.T(), err, usecase.ErrVersionMismatch)
+
+	current, _ := suite.requestUsecase.GetRequestByID(int(request.ID), adminViewer)
+	assert.Equal(suite.T(), "PENDING", current.Status)
+}
+
+// Test BulkApproveRequests reports a result per request without aborting the batch
+func (suite *RequestUsecaseTestSuite) TestBulkApproveRequests() {
+	workflow := suite.CreateTestWorkflow()
+	otherWorkflow := suite.CreateTestWorkflow()
+	manager := suite.CreateTestUser("Manager")
+
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 1, Actor: "Manager"})
+	suite.DB.Create(&model.Step{WorkflowID: otherWorkflow.ID, Level: 1, Actor: "Director"})
+
+	pending := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&pending)
+	rejected := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "REJECTED", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&rejected)
+	otherStep := model.Request{WorkflowID: otherWorkflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&otherStep)
+
+	items := []usecase.BulkItem{{ID: int(pending.ID)}, {ID: int(rejected.ID)}, {ID: int(otherStep.ID)}, {ID: 99999}, {ID: int(pending.ID)}}
+	results, err := suite.requestUsecase.BulkApproveRequests(items, manager.ID, "batch")
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 4)
+
+	assert.Equal(suite.T(), usecase.BulkResultOK, results[0].Result)
+	assert.Equal(suite.T(), "APPROVED", results[0].Request.Status)
+	assert.Equal(suite.T(), usecase.BulkResultNotPending, results[1].Result)
+	assert.Equal(suite.T(), usecase.BulkResultForbidden, results[2].Result)
+	assert.Equal(suite.T(), usecase.BulkResultNotFound, results[3].Result)
+
+	_, err = suite.requestUsecase.BulkRejectRequests(nil, manager.ID, "", "")
+	assert.Equal(suite.T(), usecase.ErrBulkEmpty, err)
+}
+
+// Test bulk items are checked against their version and that an unmet API
+// threshold is not reported as ok
+func (suite *RequestUsecaseTestSuite) TestBulkApproveRequests_VersionAndThreshold() {
+	workflow := suite.CreateTestWorkflow()
+	thresholdWorkflow := model.Workflow{Name: fmt.Sprintf("Bulk Threshold Workflow %d", suite.TestCounter)}
+	suite.DB.Create(&thresholdWorkflow)
+	manager := suite.CreateTestUser("Manager")
+
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 1, Actor: "Manager"})
+	suite.DB.Create(&model.Step{
+		WorkflowID: thresholdWorkflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000, "approval_type": "API"}`)),
+	})
+
+	stale := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100), BaseAmount: decimal.NewFromInt(100)}
+	suite.DB.Create(&stale)
+	below := model.Request{WorkflowID: thresholdWorkflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100), BaseAmount: decimal.NewFromInt(100)}
+	suite.DB.Create(&below)
+
+	items := []usecase.BulkItem{{ID: int(stale.ID), Version: stale.Version + 1}, {ID: int(below.ID), Version: below.Version}}
+	results, err := suite.requestUsecase.BulkApproveRequests(items, manager.ID, "")
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), results, 2) {
+		assert.Equal(suite.T(), usecase.BulkResultVersionMismatch, results[0].Result)
+		assert.Equal(suite.T(), usecase.BulkResultBelowThreshold, results[1].Result)
+		assert.Equal(suite.T(), "PENDING", results[1].Request.Status)
+	}
+
+	results, err = suite.requestUsecase.BulkRejectRequests([]usecase.BulkItem{{ID: int(stale.ID), Version: stale.Version}}, manager.ID, "", "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), usecase.BulkResultOK, results[0].Result)
+	assert.Equal(suite.T(), "REJECTED", results[0].Request.Status)
+}
+
+// Test merged amounts are accumulated exactly and compared to thresholds
+// without floating point drift
+func (suite *RequestUsecaseTestSuite) TestCreateRequest_DecimalAccumulation() {
+	workflow := suite.CreateTestWorkflow()
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": "0.3", "approval_type": "MANUAL"}`)),
+	})
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 2, Actor: "Director"})
+
+	_, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.RequireFromString("0.1"), "", nil, 0)
+	assert.NoError(suite.T(), err)
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.RequireFromString("0.2"), "idr", nil, 0)
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), "0.3", request.Amount.String())
+	assert.Equal(suite.T(), "IDR", request.Currency)
+	assert.Equal(suite.T(), uint(2), request.CurrentStep)
+
+	_, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.RequireFromString("0.00001"), "", nil, 0)
+	assert.Equal(suite.T(), usecase.ErrAmountPrecision, err)
+}
+
+// Test CreateRequest converts other currencies to the workflow currency at the
+// rate valid on the submission date
+func (suite *RequestUsecaseTestSuite) TestCreateRequest_ExchangeRate() {
+	workflow, err := suite.workflowUsecase.CreateWorkflow(fmt.Sprintf("USD Workflow %d", suite.TestCounter), "", "usd")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "USD", workflow.Currency)
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 100, "currency": "USD", "approval_type": "MANUAL"}`)),
+	})
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 2, Actor: "Director"})
+
+	_, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "US", nil, 0)
+	assert.Equal(suite.T(), usecase.ErrInvalidCurrency, err)
+
+	_, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(1600000), "IDR", nil, 0)
+	assert.Equal(suite.T(), usecase.ErrExchangeRateNotFound, err)
+
+	// Only the rate already in effect applies; the stored pair is USD->IDR
+	// and is used inverted.
+	rates := usecase.NewExchangeRateUsecase(repository.NewExchangeRateRepository(suite.DB))
+	_, err = rates.CreateExchangeRate("USD", "IDR", decimal.NewFromInt(16000), time.Now().AddDate(0, 0, -1))
+	assert.NoError(suite.T(), err)
+	_, err = rates.CreateExchangeRate("USD", "IDR", decimal.NewFromInt(20000), time.Now().AddDate(0, 0, 1))
+	assert.NoError(suite.T(), err)
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(1600000), "idr", nil, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "IDR", request.Currency)
+	assert.Equal(suite.T(), "1600000", request.Amount.String())
+	assert.Equal(suite.T(), "USD", request.BaseCurrency)
+	assert.Equal(suite.T(), "100", request.BaseAmount.String())
+	assert.Equal(suite.T(), "0.0000625", request.ExchangeRate.String())
+	assert.Equal(suite.T(), uint(2), request.CurrentStep)
+
+	// A USD amount merged into the IDR request restates it in USD.
+	merged, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(50), "", nil, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), request.ID, merged.ID)
+	assert.Equal(suite.T(), "USD", merged.Currency)
+	assert.Equal(suite.T(), "150", merged.Amount.String())
+	assert.Equal(suite.T(), "150", merged.BaseAmount.String())
+}
+
+// Test merging in the same currency keeps amount x exchange_rate equal to the
+// base amount when the rate changed since the first submission
+func (suite *RequestUsecaseTestSuite) TestCreateRequest_MergeAfterRateChange() {
+	workflow, err := suite.workflowUsecase.CreateWorkflow(fmt.Sprintf("SGD Workflow %d", suite.TestCounter), "", "SGD")
+	assert.NoError(suite.T(), err)
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 100000, "approval_type": "MANUAL"}`)),
+	})
+
+	rates := usecase.NewExchangeRateUsecase(repository.NewExchangeRateRepository(suite.DB))
+	_, err = rates.CreateExchangeRate("SGD", "JPY", decimal.NewFromInt(100), time.Now().AddDate(0, 0, -2))
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(10000), "JPY", nil, 0)
+	assert.NoError(suite.T(), err)
+
+	// Same currency at the same rate keeps adding up in JPY.
+	merged, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(5000), "JPY", nil, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "JPY", merged.Currency)
+	assert.Equal(suite.T(), "15000", merged.Amount.String())
+	assert.Equal(suite.T(), "150", merged.BaseAmount.String())
+	assert.True(suite.T(), merged.Amount.Mul(merged.ExchangeRate).Equal(merged.BaseAmount))
+
+	// A newer rate means the JPY amounts no longer share one rate, so the
+	// request is restated in SGD.
+	_, err = rates.CreateExchangeRate("SGD", "JPY", decimal.NewFromInt(125), time.Now().AddDate(0, 0, -1))
+	assert.NoError(suite.T(), err)
+
+	merged, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(5000), "JPY", nil, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "SGD", merged.Currency)
+	assert.Equal(suite.T(), "190", merged.Amount.String())
+	assert.Equal(suite.T(), "190", merged.BaseAmount.String())
+	assert.True(suite.T(), merged.Amount.Mul(merged.ExchangeRate).Equal(merged.BaseAmount))
+}
+
+// Test CreateRequest skips steps whose applies_when does not match
+func (suite *RequestUsecaseTestSuite) TestCreateRequest_SkipsStepByExpression() {
+	workflow := suite.CreateTestWorkflow()
+
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"approval_type": "MANUAL", "applies_when": "metadata.department == \"IT\""}`)),
+	})
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      2,
+		Actor:      "Director",
+		Conditions: datatypes.JSON([]byte(`{"approval_type": "MANUAL", "applies_when": "amount > 5000"}`)),
+	})
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(7000), "", datatypes.JSON([]byte(`{"department": "HR"}`)), 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(2), request.CurrentStep)
+	assert.Equal(suite.T(), "PENDING", request.Status)
+}
+
+// Test ApproveRequest moves through steps that auto-approve
+func (suite *RequestUsecaseTestSuite) TestApproveRequest_AutoApproveByExpression() {
+	workflow := suite.CreateTestWorkflow()
+	manager := suite.CreateTestUser("Manager")
+
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000, "approval_type": "MANUAL"}`)),
+	})
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      2,
+		Actor:      "Director",
+		Conditions: datatypes.JSON([]byte(`{"approval_type": "MANUAL", "auto_approve_when": "amount < 1000 || metadata.priority == 'low'"}`)),
+	})
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(500), "", datatypes.JSON([]byte(`{"priority": "high"}`)), 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(1), request.CurrentStep)
+
+	approvedRequest, err := suite.requestUsecase.ApproveRequest(int(request.ID), 0, manager.ID, "")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "APPROVED", approvedRequest.Status)
+}
+
+// Test CreateRequest refuses metadata that is not a JSON object
+func (suite *RequestUsecaseTestSuite) TestCreateRequest_InvalidMetadata() {
+	workflow := suite.CreateTestWorkflow()
+
+	_, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", datatypes.JSON([]byte(`[1, 2]`)), 0)
+	assert.Equal(suite.T(), usecase.ErrInvalidMetadata, err)
+}
+
 // Test GetRequestByID
 func (suite *RequestUsecaseTestSuite) TestGetRequestByID() {
 	workflow := suite.CreateTestWorkflow()
@@ -249,21 +1052,164 @@ func (suite *RequestUsecaseTestSuite) TestGetRequestByID() {
 		WorkflowID:  workflow.ID,
 		CurrentStep: 1,
 		Status:      "PENDING",
-		Amount:      100,
+		Amount:      decimal.NewFromInt(100),
+		BaseAmount:  decimal.NewFromInt(100),
 	}
 	suite.DB.Create(&request)
 
 	// Get request
-	fetchedRequest, err := suite.requestUsecase.GetRequestByID(int(request.ID))
+	fetchedRequest, err := suite.requestUsecase.GetRequestByID(int(request.ID), adminViewer)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), request.ID, fetchedRequest.ID)
 	assert.Equal(suite.T(), request.Amount, fetchedRequest.Amount)
 }
 
+// Test GetRequestByID and the request list show the same requests: submitted,
+// decided on, or pending on a step the viewer can act on
+func (suite *RequestUsecaseTestSuite) TestGetRequestByID_Visibility() {
+	workflow := suite.CreateTestWorkflow()
+	requester := suite.CreateTestUser()
+	manager := suite.CreateTestUser("Manager")
+	outsider := suite.CreateTestUser()
+
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000, "approval_type": "MANUAL"}`)),
+	})
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), requester.ID, request.RequesterID)
+
+	_, err = suite.requestUsecase.GetRequestByID(int(request.ID), usecase.Viewer{UserID: requester.ID})
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.requestUsecase.GetRequestByID(int(request.ID), usecase.Viewer{UserID: manager.ID})
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.requestUsecase.GetRequestByID(int(request.ID), usecase.Viewer{UserID: outsider.ID})
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+
+	_, err = suite.requestUsecase.GetRequestByID(int(request.ID), usecase.Viewer{UserID: outsider.ID, Admin: true})
+	assert.NoError(suite.T(), err)
+
+	search := fmt.Sprint(workflow.ID)
+	for _, viewer := range []model.User{requester, manager} {
+		requests, total, err := suite.requestUsecase.FindAllRequestsWithPagination(1, 10, search, "", false, usecase.Viewer{UserID: viewer.ID})
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), int64(1), total)
+		assert.Equal(suite.T(), request.ID, requests[0].ID)
+	}
+
+	_, total, err := suite.requestUsecase.FindAllRequestsWithPagination(1, 10, search, "", false, usecase.Viewer{UserID: outsider.ID})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(0), total)
+}
+
+// Test FindAllRequestsWithPagination scopes the list to the viewer
+func (suite *RequestUsecaseTestSuite) TestFindAllRequests_Mine() {
+	requester := suite.CreateTestUser()
+	other := suite.CreateTestUser()
+
+	workflow := suite.CreateTestWorkflow()
+	for _, userID := range []uint{requester.ID, other.ID, other.ID} {
+		suite.DB.Create(&model.Request{
+			WorkflowID:  workflow.ID,
+			RequesterID: userID,
+			CurrentStep: 1,
+			Status:      "PENDING",
+			Amount:      decimal.NewFromInt(100),
+			BaseAmount:  decimal.NewFromInt(100),
+		})
+	}
+
+	requests, total, err := suite.requestUsecase.FindAllRequestsWithPagination(1, 10, "", "", true, usecase.Viewer{UserID: requester.ID})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+	assert.Equal(suite.T(), requester.ID, requests[0].RequesterID)
+
+	_, total, err = suite.requestUsecase.FindAllRequestsWithPagination(1, 10, "", "", false, usecase.Viewer{UserID: other.ID})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), total)
+
+	_, total, err = suite.requestUsecase.FindAllRequestsWithPagination(1, 10, fmt.Sprint(workflow.ID), "", false, adminViewer)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), total)
+}
+
+// Test FindInboxWithPagination lists pending requests the user can act on, oldest first
+func (suite *RequestUsecaseTestSuite) TestFindInbox() {
+	workflow := suite.CreateTestWorkflow()
+	approver := suite.CreateTestUser("InboxApprover")
+	member := suite.CreateTestUser()
+	group := suite.CreateTestGroup(approver, member)
+
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 1, Actor: "inboxapprover"})
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 2, Actor: "group:" + group.Name})
+	suite.DB.Create(&model.Step{WorkflowID: workflow.ID, Level: 3, Actor: fmt.Sprintf("user:%d", member.ID)})
+
+	older := model.Request{WorkflowID: workflow.ID, CurrentStep: 2, Status: "PENDING", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&older)
+	newer := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&newer)
+	suite.DB.Create(&model.Request{WorkflowID: workflow.ID, CurrentStep: 3, Status: "PENDING", Amount: decimal.NewFromInt(100), BaseAmount: decimal.NewFromInt(100)})
+	suite.DB.Create(&model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "APPROVED", Amount: decimal.NewFromInt(100), BaseAmount: decimal.NewFromInt(100)})
+	voted := model.Request{WorkflowID: workflow.ID, CurrentStep: 2, Status: "PENDING", Amount: decimal.NewFromInt(100)}
+	suite.DB.Create(&voted)
+	suite.DB.Create(&model.Approval{RequestID: voted.ID, StepLevel: 2, UserID: approver.ID, Decision: "APPROVED"})
+
+	requests, total, err := suite.requestUsecase.FindInboxWithPagination(1, 10, approver.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), total)
+	if assert.Len(suite.T(), requests, 2) {
+		assert.Equal(suite.T(), older.ID, requests[0].ID)
+		assert.Equal(suite.T(), newer.ID, requests[1].ID)
+	}
+
+	_, total, err = suite.requestUsecase.FindInboxWithPagination(1, 10, member.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), total)
+}
+
+// Test steps saved with loosely formatted actors still land in the inbox
+func (suite *RequestUsecaseTestSuite) TestFindInbox_CanonicalActors() {
+	role := fmt.Sprintf("Canonical Approver %d", suite.TestCounter)
+	approver := suite.CreateTestUser(role)
+	member := suite.CreateTestUser()
+	group := suite.CreateTestGroup(member)
+
+	actors := []struct {
+		userID uint
+		actor  string
+		stored string
+	}{
+		{approver.ID, " Role :  " + strings.ToUpper(role) + " ", "role:" + role},
+		{member.ID, fmt.Sprintf("USER: 000%d", member.ID), fmt.Sprintf("user:%d", member.ID)},
+		{member.ID, "group : " + group.Name, "group:" + group.Name},
+	}
+	for i, tc := range actors {
+		workflow := model.Workflow{Name: fmt.Sprintf("Canonical Workflow %d-%d", suite.TestCounter, i)}
+		suite.DB.Create(&workflow)
+
+		step, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, tc.actor, nil)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), tc.stored, step.Actor)
+
+		request := model.Request{WorkflowID: workflow.ID, CurrentStep: 1, Status: "PENDING", Amount: decimal.NewFromInt(100), BaseAmount: decimal.NewFromInt(100)}
+		suite.DB.Create(&request)
+
+		requests, _, err := suite.requestUsecase.FindInboxWithPagination(1, 10, tc.userID)
+		assert.NoError(suite.T(), err)
+		assert.Contains(suite.T(), requestIDs(requests), request.ID, tc.actor)
+	}
+}
+
 // Test GetRequestByID with non-existent ID
 func (suite *RequestUsecaseTestSuite) TestGetRequestByID_NotFound() {
-	_, err := suite.requestUsecase.GetRequestByID(9999)
+	_, err := suite.requestUsecase.GetRequestByID(9999, adminViewer)
 
 	assert.Error(suite.T(), err)
 }
@@ -282,15 +1228,110 @@ func (suite *RequestUsecaseTestSuite) TestRequestAccumulation() {
 	suite.DB.Create(&step)
 
 	// Create first request
-	request1, err := suite.requestUsecase.CreateRequest(int(workflow.ID), 60)
+	request1, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(60), "", nil, 0)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "PENDING", request1.Status)
 
 	// Create second request (should accumulate)
-	request2, err := suite.requestUsecase.CreateRequest(int(workflow.ID), 50)
+	request2, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(50), "", nil, 0)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "APPROVED", request2.Status)
-	assert.Equal(suite.T(), 110.0, request2.Amount)
+	assert.Equal(suite.T(), "110", request2.Amount.String())
+}
+
+// Test CreateRequest honours the submission mode of the workflow
+func (suite *RequestUsecaseTestSuite) TestCreateRequest_SubmissionMode() {
+	_, err := suite.workflowUsecase.CreateWorkflow(fmt.Sprintf("Claims %d", suite.TestCounter), "batch", "")
+	assert.Equal(suite.T(), usecase.ErrInvalidSubmissionMode, err)
+
+	workflow, err := suite.workflowUsecase.CreateWorkflow(fmt.Sprintf("Claims %d", suite.TestCounter), model.SubmissionModeSeparate, "")
+	assert.NoError(suite.T(), err)
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 1000, "approval_type": "MANUAL"}`)),
+	})
+
+	first, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(60), "", nil, 0)
+	assert.NoError(suite.T(), err)
+	second, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(50), "", nil, 0)
+	assert.NoError(suite.T(), err)
+
+	assert.NotEqual(suite.T(), first.ID, second.ID)
+	assert.Equal(suite.T(), model.SubmissionModeSeparate, second.SubmissionMode)
+	assert.Equal(suite.T(), "50", second.Amount.String())
+	assert.Equal(suite.T(), "PENDING", first.Status)
+	assert.Equal(suite.T(), "PENDING", second.Status)
+
+	// Default workflows keep merging into the pending request.
+	merging := suite.CreateTestWorkflow()
+	assert.Equal(suite.T(), model.SubmissionModeMerge, merging.SubmissionMode)
+}
+
+// Test FindHistoryByRequestID records the lifecycle of a request in order
+func (suite *RequestUsecaseTestSuite) TestFindHistoryByRequestID() {
+	workflow := suite.CreateTestWorkflow()
+	requester := suite.CreateTestUser()
+	director := suite.CreateTestUser("Director")
+
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      1,
+		Actor:      "Manager",
+		Conditions: datatypes.JSON([]byte(`{"min_amount": 100, "approval_type": "API"}`)),
+	})
+	suite.DB.Create(&model.Step{
+		WorkflowID: workflow.ID,
+		Level:      2,
+		Actor:      "Director",
+		Conditions: datatypes.JSON([]byte(`{"approval_type": "MANUAL"}`)),
+	})
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(60), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(50), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, director.ID, "")
+	assert.NoError(suite.T(), err)
+
+	events, err := suite.requestUsecase.FindHistoryByRequestID(int(request.ID), adminViewer)
+	assert.NoError(suite.T(), err)
+
+	var types []string
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(suite.T(), []string{
+		model.RequestEventCreated,
+		model.RequestEventAmountMerged,
+		model.RequestEventStepAdvanced,
+		model.RequestEventApproved,
+	}, types)
+
+	assert.Nil(suite.T(), events[0].Before)
+	assert.JSONEq(suite.T(), `{"status": "PENDING", "current_step": 1, "amount": "60"}`, string(events[1].Before))
+	assert.JSONEq(suite.T(), `{"status": "PENDING", "current_step": 1, "amount": "110"}`, string(events[1].After))
+	assert.JSONEq(suite.T(), `{"status": "PENDING", "current_step": 2, "amount": "110"}`, string(events[2].After))
+	assert.Equal(suite.T(), requester.ID, *events[1].ActorID)
+	assert.Equal(suite.T(), director.ID, *events[3].ActorID)
+	assert.Equal(suite.T(), director.ID, events[3].Actor.ID)
+}
+
+// Test FindHistoryByRequestID with non-existent request
+func (suite *RequestUsecaseTestSuite) TestFindHistoryByRequestID_NotFound() {
+	_, err := suite.requestUsecase.FindHistoryByRequestID(9999, adminViewer)
+	assert.Error(suite.T(), err)
+}
+
+func requestIDs(requests []model.Request) []uint {
+	ids := make([]uint, 0, len(requests))
+	for _, request := range requests {
+		ids = append(ids, request.ID)
+	}
+	return ids
 }
 
 // Run the test suite
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"fmt"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestStreamTestSuite struct {
+	BaseTestSuite
+	requestUsecase usecase.RequestUsecase
+	outbox         usecase.OutboxDispatcher
+}
+
+func (suite *RequestStreamTestSuite) SetupTest() {
+	err := suite.InitializeDB("request_stream")
+	suite.NoError(err)
+
+	suite.requestUsecase, _, _ = suite.CreateRequestUsecaseWithDeps()
+	suite.outbox = usecase.NewOutboxDispatcher(repository.NewOutboxRepository(suite.DB), suite.Bus)
+}
+
+func (suite *RequestStreamTestSuite) lastEventID() uint {
+	var event model.RequestEvent
+	suite.DB.Order("id DESC").Limit(1).Find(&event)
+	return event.ID
+}
+
+// collect reads events until the stream stays silent.
+func collect(stream usecase.RequestStream) []usecase.RequestEventMessage {
+	var messages []usecase.RequestEventMessage
+	for {
+		message, ok, err := stream.Next(20 * time.Millisecond)
+		if err != nil || !ok {
+			return messages
+		}
+		messages = append(messages, message)
+	}
+}
+
+// Test a resumed stream replays the events after the last event ID
+func (suite *RequestStreamTestSuite) TestOpenRequestStream_Resume() {
+	approver := suite.CreateTestUser("Manager")
+	workflow := suite.CreateTestWorkflowWithSteps("Stream Workflow", fmt.Sprintf("user:%d", approver.ID))
+	requester := suite.CreateTestUser("Requester")
+	resumeFrom := suite.lastEventID()
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
+	assert.NoError(suite.T(), err)
+
+	stream, err := suite.requestUsecase.OpenRequestStream(resumeFrom, usecase.StreamFilter{WorkflowID: workflow.ID}, adminViewer)
+	assert.NoError(suite.T(), err)
+	defer stream.Close()
+
+	messages := collect(stream)
+	assert.Len(suite.T(), messages, 2)
+	assert.Equal(suite.T(), model.RequestEventCreated, messages[0].Type)
+	assert.Equal(suite.T(), model.RequestEventApproved, messages[1].Type)
+	assert.Equal(suite.T(), workflow.ID, messages[1].WorkflowID)
+	assert.Greater(suite.T(), messages[1].EventID, messages[0].EventID)
+
+	// Events already replayed are not sent again when they are published
+	_, err = suite.outbox.DispatchPending(time.Now())
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), collect(stream))
+
+	// Status filter
+	approved, err := suite.requestUsecase.OpenRequestStream(resumeFrom, usecase.StreamFilter{WorkflowID: workflow.ID, Status: "approved"}, adminViewer)
+	assert.NoError(suite.T(), err)
+	defer approved.Close()
+
+	messages = collect(approved)
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), model.RequestEventApproved, messages[0].Type)
+}
+
+// Test live events are filtered by visibility and assignment
+func (suite *RequestStreamTestSuite) TestOpenRequestStream_Live() {
+	approver := suite.CreateTestUser("Manager")
+	workflow := suite.CreateTestWorkflowWithSteps("Stream Workflow", fmt.Sprintf("user:%d", approver.ID))
+	requester := suite.CreateTestUser("Requester")
+	outsider := suite.CreateTestUser("Requester")
+
+	// Publish whatever earlier tests left in the outbox
+	_, err := suite.outbox.DispatchPending(time.Now())
+	assert.NoError(suite.T(), err)
+
+	assigned, err := suite.requestUsecase.OpenRequestStream(0, usecase.StreamFilter{AssignedToMe: true}, usecase.Viewer{UserID: approver.ID})
+	assert.NoError(suite.T(), err)
+	defer assigned.Close()
+
+	own, err := suite.requestUsecase.OpenRequestStream(0, usecase.StreamFilter{}, usecase.Viewer{UserID: requester.ID})
+	assert.NoError(suite.T(), err)
+	defer own.Close()
+
+	hidden, err := suite.requestUsecase.OpenRequestStream(0, usecase.StreamFilter{}, usecase.Viewer{UserID: outsider.ID})
+	assert.NoError(suite.T(), err)
+	defer hidden.Close()
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	_, err = suite.outbox.DispatchPending(time.Now())
+	assert.NoError(suite.T(), err)
+
+	messages := collect(assigned)
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), request.ID, messages[0].RequestID)
+
+	messages = collect(own)
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), model.RequestEventCreated, messages[0].Type)
+
+	assert.Empty(suite.T(), collect(hidden))
+
+	// Once approved the request is no longer assigned to the approver
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
+	assert.NoError(suite.T(), err)
+	_, err = suite.outbox.DispatchPending(time.Now())
+	assert.NoError(suite.T(), err)
+
+	assert.Empty(suite.T(), collect(assigned))
+	messages = collect(own)
+	assert.Len(suite.T(), messages, 1)
+	assert.Equal(suite.T(), model.RequestEventApproved, messages[0].Type)
+}
+
+// Test a replay cut short by the limit ends with a reset message
+func (suite *RequestStreamTestSuite) TestOpenRequestStream_ReplayLimit() {
+	approver := suite.CreateTestUser("Manager")
+	workflow := suite.CreateTestWorkflowWithSteps("Stream Workflow", fmt.Sprintf("user:%d", approver.ID))
+	requester := suite.CreateTestUser("Requester")
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, requester.ID)
+	assert.NoError(suite.T(), err)
+	resumeFrom := suite.lastEventID()
+
+	events := make([]model.RequestEvent, 1001)
+	for i := range events {
+		events[i] = model.RequestEvent{RequestID: request.ID, Type: model.RequestEventAmountMerged}
+	}
+	assert.NoError(suite.T(), suite.DB.CreateInBatches(&events, 200).Error)
+
+	stream, err := suite.requestUsecase.OpenRequestStream(resumeFrom, usecase.StreamFilter{WorkflowID: workflow.ID}, usecase.Viewer{UserID: requester.ID})
+	assert.NoError(suite.T(), err)
+	defer stream.Close()
+
+	messages := collect(stream)
+	assert.Len(suite.T(), messages, 1001)
+	assert.Equal(suite.T(), events[999].ID, messages[999].EventID)
+	assert.Equal(suite.T(), usecase.StreamEventReset, messages[1000].Type)
+	assert.Equal(suite.T(), suite.lastEventID(), messages[1000].EventID)
+
+	// Live events up to the reset point are covered by the reset
+	_, err = suite.outbox.DispatchPending(time.Now())
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), collect(stream))
+}
+
+func TestRequestStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestStreamTestSuite))
+}
@@ -1,20 +1,25 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
 	"technical-test/src/model"
 	"technical-test/src/repository"
 	"technical-test/src/usecase"
+	"time"
 
 	"github.com/stretchr/testify/suite"
+	"gorm.io/datatypes"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 type BaseTestSuite struct {
 	suite.Suite
 	DB          *gorm.DB
 	TestCounter int
+	Bus         *usecase.EventBus
 }
 
 func (suite *BaseTestSuite) InitializeDB(suiteName string) error {
@@ -31,7 +36,7 @@ func (suite *BaseTestSuite) InitializeDB(suiteName string) error {
 
 	// Use named shared in-memory database to avoid "no such table" across pooled connections.
 	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", suiteName)
-	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return err
 	}
@@ -44,42 +49,158 @@ func (suite *BaseTestSuite) InitializeDB(suiteName string) error {
 	sqlDB.SetMaxOpenConns(1)
 	sqlDB.SetMaxIdleConns(1)
 
-	err = db.AutoMigrate(
-		&model.User{},
-		&model.Workflow{},
-		&model.Step{},
-		&model.Request{},
-	)
+	if err := migrate(db); err != nil {
+		return err
+	}
+
+	suite.DB = db
+	return nil
+}
+
+// InitializeFileDB opens a SQLite database file that allows concurrent
+// connections. Transactions start with BEGIN IMMEDIATE so writers queue on the
+// database lock (up to the busy timeout) instead of failing. This runs every
+// transaction one at a time, so it does not exercise the MySQL row locks;
+// tests that need a lost race have to stage it themselves.
+func (suite *BaseTestSuite) InitializeFileDB(path string) error {
+	dsn := fmt.Sprintf("file:%s?_txlock=immediate&_busy_timeout=10000&_journal_mode=WAL", path)
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
 	if err != nil {
 		return err
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(10)
+
+	if err := migrate(db); err != nil {
+		return err
+	}
+
 	suite.DB = db
 	return nil
 }
 
+func migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&model.User{},
+		&model.Workflow{},
+		&model.Step{},
+		&model.Request{},
+		&model.Approval{},
+		&model.RequestEvent{},
+		&model.UserRole{},
+		&model.Role{},
+		&model.Group{},
+		&model.GroupMember{},
+		&model.IdempotencyKey{},
+		&model.ExchangeRate{},
+		&model.WebhookSubscription{},
+		&model.WebhookDelivery{},
+		&model.OutboxEvent{},
+		&model.NotificationTemplate{},
+		&model.RefreshToken{},
+		&model.DataMigration{},
+	)
+}
+
 func (suite *BaseTestSuite) CreateTestWorkflow() model.Workflow {
 	workflow := model.Workflow{Name: fmt.Sprintf("Test Workflow %d", suite.TestCounter)}
 	suite.DB.Create(&workflow)
 	return workflow
 }
 
+// CreateTestWorkflowWithSteps creates a workflow with one MANUAL step per actor,
+// in level order. The first level has a min_amount of 1000, so smaller
+// requests are approved after it.
+func (suite *BaseTestSuite) CreateTestWorkflowWithSteps(name string, actors ...string) model.Workflow {
+	workflow := model.Workflow{Name: fmt.Sprintf("%s %d-%d", name, suite.TestCounter, time.Now().UnixNano())}
+	suite.DB.Create(&workflow)
+
+	for i, actor := range actors {
+		conditions := `{"approval_type": "MANUAL"}`
+		if i == 0 {
+			conditions = `{"min_amount": 1000, "approval_type": "MANUAL"}`
+		}
+		suite.DB.Create(&model.Step{
+			WorkflowID: workflow.ID,
+			Level:      uint(i + 1),
+			Actor:      actor,
+			Conditions: datatypes.JSON([]byte(conditions)),
+		})
+	}
+	return workflow
+}
+
+func (suite *BaseTestSuite) CreateTestUser(roles ...string) model.User {
+	user := model.User{
+		Name:         fmt.Sprintf("Test User %d", suite.TestCounter),
+		Email:        fmt.Sprintf("user%d_%d@example.com", suite.TestCounter, time.Now().UnixNano()),
+		PasswordHash: "hash",
+	}
+	suite.DB.Create(&user)
+
+	if len(roles) > 0 {
+		suite.CreateTestRoles(roles...)
+		_, err := usecase.NewUserUsecase(repository.NewUserRepository(suite.DB), repository.NewRoleRepository(suite.DB)).UpdateUserRoles(int(user.ID), roles)
+		suite.Require().NoError(err)
+	}
+	return user
+}
+
+// CreateTestRoles creates the custom roles that do not exist yet, the same way
+// an admin would before assigning them or using them as step actors.
+func (suite *BaseTestSuite) CreateTestRoles(names ...string) {
+	roleUsecase := usecase.NewRoleUsecase(repository.NewRoleRepository(suite.DB))
+	for _, name := range names {
+		if _, err := roleUsecase.CreateRole(name, 0); err != nil && !errors.Is(err, usecase.ErrRoleExists) {
+			suite.Require().NoError(err)
+		}
+	}
+}
+
+func (suite *BaseTestSuite) CreateTestGroup(members ...model.User) model.Group {
+	group := model.Group{Name: fmt.Sprintf("group-%d-%d", suite.TestCounter, time.Now().UnixNano())}
+	suite.DB.Create(&group)
+
+	for _, member := range members {
+		suite.DB.Create(&model.GroupMember{GroupID: group.ID, UserID: member.ID})
+	}
+	return group
+}
+
 func (suite *BaseTestSuite) CreateRequestUsecaseWithDeps() (usecase.RequestUsecase, usecase.WorkflowUsecase, usecase.StepUsecase) {
 	workflowRepo := repository.NewWorkflowRepository(suite.DB)
 	stepRepo := repository.NewStepRepository(suite.DB)
 	requestRepo := repository.NewRequestRepository(suite.DB)
+	approvalRepo := repository.NewApprovalRepository(suite.DB)
+	eventRepo := repository.NewRequestEventRepository(suite.DB)
+	userRepo := repository.NewUserRepository(suite.DB)
+	groupRepo := repository.NewGroupRepository(suite.DB)
+	roleRepo := repository.NewRoleRepository(suite.DB)
+	exchangeRateRepo := repository.NewExchangeRateRepository(suite.DB)
+	outboxRepo := repository.NewOutboxRepository(suite.DB)
+	suite.Bus = usecase.NewEventBus()
 
 	workflowUsecase := usecase.NewWorkflowUsecase(workflowRepo)
-	stepUsecase := usecase.NewStepUsecase(stepRepo, workflowRepo)
-	requestUsecase := usecase.NewRequestUsecase(requestRepo, stepRepo, workflowRepo)
+	stepUsecase := usecase.NewStepUsecase(stepRepo, workflowRepo, userRepo, groupRepo, roleRepo)
+	requestUsecase := usecase.NewRequestUsecase(requestRepo, stepRepo, workflowRepo, approvalRepo, eventRepo, exchangeRateRepo, outboxRepo, suite.Bus, userRepo, groupRepo)
 	return requestUsecase, workflowUsecase, stepUsecase
 }
 
 func (suite *BaseTestSuite) CreateStepUsecaseWithDeps() (usecase.StepUsecase, usecase.WorkflowUsecase) {
 	workflowRepo := repository.NewWorkflowRepository(suite.DB)
 	stepRepo := repository.NewStepRepository(suite.DB)
+	userRepo := repository.NewUserRepository(suite.DB)
+	groupRepo := repository.NewGroupRepository(suite.DB)
+	roleRepo := repository.NewRoleRepository(suite.DB)
 
 	workflowUsecase := usecase.NewWorkflowUsecase(workflowRepo)
-	stepUsecase := usecase.NewStepUsecase(stepRepo, workflowRepo)
+	stepUsecase := usecase.NewStepUsecase(stepRepo, workflowRepo, userRepo, groupRepo, roleRepo)
 	return stepUsecase, workflowUsecase
 }
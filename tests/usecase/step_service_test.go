@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"fmt"
 	"technical-test/src/usecase"
 	"testing"
 
@@ -20,23 +21,24 @@ func (suite *StepUsecaseTestSuite) SetupTest() {
 	suite.NoError(err)
 
 	suite.stepUsecase, suite.workflowUsecase = suite.CreateStepUsecaseWithDeps()
+	suite.CreateTestRoles("Manager", "Director", "CEO")
 }
 
 func (suite *StepUsecaseTestSuite) TestCreateStep_Valid() {
 	workflow := suite.CreateTestWorkflow()
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100, "approval_type": "API"}`))
-	step, err := suite.stepUsecase.CreateStep(int(workflow.ID), "Manager", conditions)
+	step, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), workflow.ID, step.WorkflowID)
-	assert.Equal(suite.T(), "Manager", step.Actor)
+	assert.Equal(suite.T(), "role:Manager", step.Actor)
 	assert.Equal(suite.T(), uint(1), step.Level)
 }
 
 func (suite *StepUsecaseTestSuite) TestCreateStep_NonExistentWorkflow() {
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
-	_, err := suite.stepUsecase.CreateStep(9999, "Manager", conditions)
+	_, err := suite.stepUsecase.CreateStep(9999, 0, "Manager", conditions)
 
 	assert.Error(suite.T(), err)
 }
@@ -46,26 +48,149 @@ func (suite *StepUsecaseTestSuite) TestCreateStep_IncrementalLevels() {
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
 
-	step1, err := suite.stepUsecase.CreateStep(int(workflow.ID), "Manager", conditions)
+	step1, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), uint(1), step1.Level)
 
-	step2, err := suite.stepUsecase.CreateStep(int(workflow.ID), "Director", conditions)
+	step2, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Director", conditions)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), uint(2), step2.Level)
 
-	step3, err := suite.stepUsecase.CreateStep(int(workflow.ID), "CEO", conditions)
+	step3, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "CEO", conditions)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), uint(3), step3.Level)
 }
 
+func (suite *StepUsecaseTestSuite) TestCreateStep_ActorPrincipals() {
+	workflow := suite.CreateTestWorkflow()
+	user := suite.CreateTestUser()
+	group := suite.CreateTestGroup(user)
+
+	_, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, fmt.Sprintf("user:%d", user.ID), nil)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "group:"+group.Name, nil)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "role:approver", nil)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *StepUsecaseTestSuite) TestCreateStep_CanonicalActor() {
+	workflow := suite.CreateTestWorkflow()
+	user := suite.CreateTestUser()
+
+	step, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, fmt.Sprintf(" User : 0%d ", user.ID), nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fmt.Sprintf("user:%d", user.ID), step.Actor)
+
+	step, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "ROLE: manager", nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "role:Manager", step.Actor)
+
+	step, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Approver", nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "role:approver", step.Actor)
+}
+
+func (suite *StepUsecaseTestSuite) TestCreateStep_InvalidActor() {
+	workflow := suite.CreateTestWorkflow()
+
+	_, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "team:finance", nil)
+	assert.Equal(suite.T(), usecase.ErrInvalidActor, err)
+
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "user:abc", nil)
+	assert.Equal(suite.T(), usecase.ErrInvalidActor, err)
+
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "user:9999", nil)
+	assert.Equal(suite.T(), usecase.ErrActorNotFound, err)
+
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "group:does-not-exist", nil)
+	assert.Equal(suite.T(), usecase.ErrActorNotFound, err)
+
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Treasurer", nil)
+	assert.Equal(suite.T(), usecase.ErrActorNotFound, err)
+}
+
+func (suite *StepUsecaseTestSuite) TestCreateStep_InvalidQuorum() {
+	workflow := suite.CreateTestWorkflow()
+
+	conditions := datatypes.JSON([]byte(`{"quorum": {"rule": "n_of_m"}}`))
+	_, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.Equal(suite.T(), usecase.ErrInvalidQuorum, err)
+
+	conditions = datatypes.JSON([]byte(`{"quorum": {"rule": "majority"}}`))
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.Equal(suite.T(), usecase.ErrInvalidQuorum, err)
+
+	conditions = datatypes.JSON([]byte(`{"quorum": {"rule": "n_of_m", "required": 1, "weights": {"1": 0}}}`))
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.Equal(suite.T(), usecase.ErrInvalidQuorum, err)
+
+	conditions = datatypes.JSON([]byte(`{"quorum": {"rule": "n_of_m", "required": 1, "weights": {"1": -2}}}`))
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.Equal(suite.T(), usecase.ErrInvalidQuorum, err)
+}
+
+func (suite *StepUsecaseTestSuite) TestCreateStep_UnreachableQuorum() {
+	workflow := suite.CreateTestWorkflow()
+	first := suite.CreateTestUser()
+	second := suite.CreateTestUser()
+	group := suite.CreateTestGroup(first, second)
+	actor := "group:" + group.Name
+
+	conditions := datatypes.JSON([]byte(`{"quorum": {"rule": "n_of_m", "required": 3}}`))
+	_, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, actor, conditions)
+	assert.Equal(suite.T(), usecase.ErrQuorumUnreachable, err)
+
+	conditions = datatypes.JSON([]byte(fmt.Sprintf(`{"quorum": {"rule": "n_of_m", "required": 3, "weights": {"%d": 2}}}`, first.ID)))
+	step, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, actor, conditions)
+	assert.NoError(suite.T(), err)
+
+	conditions = datatypes.JSON([]byte(`{"quorum": {"rule": "n_of_m", "required": 4}}`))
+	_, err = suite.stepUsecase.UpdateStep(int(step.ID), step.Version, step.Level, actor, conditions)
+	assert.Equal(suite.T(), usecase.ErrQuorumUnreachable, err)
+
+	suite.CreateTestRoles("Auditor")
+	conditions = datatypes.JSON([]byte(`{"quorum": {"rule": "all"}}`))
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Auditor", conditions)
+	assert.Equal(suite.T(), usecase.ErrQuorumUnreachable, err)
+}
+
+func (suite *StepUsecaseTestSuite) TestCreateStep_InvalidExpression() {
+	workflow := suite.CreateTestWorkflow()
+
+	conditions := datatypes.JSON([]byte(`{"applies_when": "amount >"}`))
+	_, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidExpression)
+
+	conditions = datatypes.JSON([]byte(`{"auto_approve_when": "salary > 10"}`))
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidExpression)
+
+	conditions = datatypes.JSON([]byte(`{"applies_when": "amount > 5000 && metadata.department == 'IT'"}`))
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *StepUsecaseTestSuite) TestCreateStep_CurrencyMismatch() {
+	workflow := suite.CreateTestWorkflow()
+
+	_, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", datatypes.JSON([]byte(`{"min_amount": 100, "currency": "USD"}`)))
+	assert.Equal(suite.T(), usecase.ErrCurrencyMismatch, err)
+
+	step, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", datatypes.JSON([]byte(`{"min_amount": "100.50", "currency": "IDR"}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(1), step.Level)
+}
+
 func (suite *StepUsecaseTestSuite) TestGetNextLevelForWorkflow() {
 	workflow := suite.CreateTestWorkflow()
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
 
-	suite.stepUsecase.CreateStep(int(workflow.ID), "Manager", conditions)
-	suite.stepUsecase.CreateStep(int(workflow.ID), "Director", conditions)
+	suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Director", conditions)
 
 	nextLevel, err := suite.stepUsecase.GetNextLevelForWorkflow(int(workflow.ID))
 
@@ -78,8 +203,8 @@ func (suite *StepUsecaseTestSuite) TestFindStepsByWorkflowID() {
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
 
-	suite.stepUsecase.CreateStep(int(workflow.ID), "Manager", conditions)
-	suite.stepUsecase.CreateStep(int(workflow.ID), "Director", conditions)
+	suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Director", conditions)
 
 	steps, err := suite.stepUsecase.FindStepsByWorkflowID(int(workflow.ID))
 
@@ -98,13 +223,13 @@ func (suite *StepUsecaseTestSuite) TestFindStepByLevelAndWorkflowID() {
 	workflow := suite.CreateTestWorkflow()
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
-	createdStep, _ := suite.stepUsecase.CreateStep(int(workflow.ID), "Manager", conditions)
+	createdStep, _ := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
 
 	step, err := suite.stepUsecase.FindStepByLevelAndWorkflowID(1, int(workflow.ID))
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), createdStep.ID, step.ID)
-	assert.Equal(suite.T(), "Manager", step.Actor)
+	assert.Equal(suite.T(), "role:Manager", step.Actor)
 }
 
 func (suite *StepUsecaseTestSuite) TestFindStepByLevelAndWorkflowID_NotFound() {
@@ -119,7 +244,7 @@ func (suite *StepUsecaseTestSuite) TestGetStepByID() {
 	workflow := suite.CreateTestWorkflow()
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
-	createdStep, _ := suite.stepUsecase.CreateStep(int(workflow.ID), "Manager", conditions)
+	createdStep, _ := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
 
 	step, err := suite.stepUsecase.GetStepByID(int(createdStep.ID))
 
@@ -137,23 +262,81 @@ func (suite *StepUsecaseTestSuite) TestUpdateStep() {
 	workflow := suite.CreateTestWorkflow()
 
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
-	createdStep, _ := suite.stepUsecase.CreateStep(int(workflow.ID), "Manager", conditions)
+	createdStep, _ := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
 
 	newConditions := datatypes.JSON([]byte(`{"min_amount": 200}`))
-	updatedStep, err := suite.stepUsecase.UpdateStep(int(createdStep.ID), 1, "Director", newConditions)
+	updatedStep, err := suite.stepUsecase.UpdateStep(int(createdStep.ID), 0, 1, "Director", newConditions)
 
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "Director", updatedStep.Actor)
+	assert.Equal(suite.T(), "role:Director", updatedStep.Actor)
 	assert.Equal(suite.T(), newConditions, updatedStep.Conditions)
 }
 
 func (suite *StepUsecaseTestSuite) TestUpdateStep_NotFound() {
 	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
-	_, err := suite.stepUsecase.UpdateStep(9999, 1, "Manager", conditions)
+	_, err := suite.stepUsecase.UpdateStep(9999, 0, 1, "Manager", conditions)
 
 	assert.Error(suite.T(), err)
 }
 
+func (suite *StepUsecaseTestSuite) TestUpdateStep_StaleVersion() {
+	workflow := suite.CreateTestWorkflow()
+
+	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
+	createdStep, _ := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.Equal(suite.T(), uint(1), createdStep.Version)
+
+	updatedStep, err := suite.stepUsecase.UpdateStep(int(createdStep.ID), createdStep.Version, 1, "Director", conditions)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(2), updatedStep.Version)
+
+	// A second writer still holding version 1 must not overwrite the change.
+	_, err = suite.stepUsecase.UpdateStep(int(createdStep.ID), createdStep.Version, 1, "CEO", conditions)
+	assert.ErrorIs(suite.T(), err, usecase.ErrVersionMismatch)
+
+	step, _ := suite.stepUsecase.GetStepByID(int(createdStep.ID))
+	assert.Equal(suite.T(), "role:Director", step.Actor)
+}
+
+// Test updating a step changes the ETag of its workflow, as creating one does
+func (suite *StepUsecaseTestSuite) TestUpdateStep_BumpsWorkflowVersion() {
+	workflow := suite.CreateTestWorkflow()
+
+	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
+	createdStep, err := suite.stepUsecase.CreateStep(int(workflow.ID), 0, "Manager", conditions)
+	assert.NoError(suite.T(), err)
+
+	before, _ := suite.workflowUsecase.GetWorkflowByID(int(workflow.ID))
+
+	_, err = suite.stepUsecase.UpdateStep(int(createdStep.ID), createdStep.Version, 1, "Director", conditions)
+	assert.NoError(suite.T(), err)
+
+	after, _ := suite.workflowUsecase.GetWorkflowByID(int(workflow.ID))
+	assert.Equal(suite.T(), before.Version+1, after.Version)
+
+	// A rejected update leaves the workflow version alone
+	_, err = suite.stepUsecase.UpdateStep(int(createdStep.ID), createdStep.Version, 1, "CEO", conditions)
+	assert.ErrorIs(suite.T(), err, usecase.ErrVersionMismatch)
+
+	unchanged, _ := suite.workflowUsecase.GetWorkflowByID(int(workflow.ID))
+	assert.Equal(suite.T(), after.Version, unchanged.Version)
+}
+
+func (suite *StepUsecaseTestSuite) TestCreateStep_BumpsWorkflowVersion() {
+	workflow := suite.CreateTestWorkflow()
+	assert.Equal(suite.T(), uint(1), workflow.Version)
+
+	conditions := datatypes.JSON([]byte(`{"min_amount": 100}`))
+	_, err := suite.stepUsecase.CreateStep(int(workflow.ID), workflow.Version, "Manager", conditions)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.stepUsecase.CreateStep(int(workflow.ID), workflow.Version, "Director", conditions)
+	assert.ErrorIs(suite.T(), err, usecase.ErrVersionMismatch)
+
+	updated, _ := suite.workflowUsecase.GetWorkflowByID(int(workflow.ID))
+	assert.Equal(suite.T(), uint(2), updated.Version)
+}
+
 func TestStepUsecaseTestSuite(t *testing.T) {
 	suite.Run(t, new(StepUsecaseTestSuite))
 }
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"technical-test/src/model"
+	"technical-test/src/repository"
+	"technical-test/src/usecase"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/datatypes"
+)
+
+type WebhookUsecaseTestSuite struct {
+	BaseTestSuite
+	webhookUsecase usecase.WebhookUsecase
+	requestUsecase usecase.RequestUsecase
+	outbox         usecase.OutboxDispatcher
+}
+
+func (suite *WebhookUsecaseTestSuite) SetupTest() {
+	err := suite.InitializeDB("webhook_usecase")
+	suite.NoError(err)
+
+	suite.requestUsecase, _, _ = suite.CreateRequestUsecaseWithDeps()
+	webhookRepo := repository.NewWebhookRepository(suite.DB)
+	suite.webhookUsecase = usecase.NewWebhookUsecase(webhookRepo, repository.NewWorkflowRepository(suite.DB))
+	suite.outbox = usecase.NewOutboxDispatcher(repository.NewOutboxRepository(suite.DB), usecase.NewWebhookSink(webhookRepo))
+}
+
+// webhookReceiver records the calls it gets and answers with the queued status
+// codes, then with 200.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	calls    []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (suite *WebhookUsecaseTestSuite) deliveriesOf(subscriptionID uint) []model.WebhookDelivery {
+	var deliveries []model.WebhookDelivery
+	suite.DB.Where("subscription_id = ?", subscriptionID).Order("id ASC").Find(&deliveries)
+	return deliveries
+}
+
+// Test signed deliveries of the events selected by a workflow subscription
+func (suite *WebhookUsecaseTestSuite) TestDispatchDue_SignedDelivery() {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	approver := suite.CreateTestUser("Manager")
+	workflow := suite.CreateTestWorkflowWithSteps("Subscribed", "Manager")
+	other := suite.CreateTestWorkflowWithSteps("Other", "Manager")
+
+	subscription, secret, err := suite.webhookUsecase.CreateSubscription(server.URL, "", &workflow.ID, nil, approver.ID)
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), secret)
+
+	request, err := suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, approver.ID)
+	assert.NoError(suite.T(), err)
+	_, err = suite.requestUsecase.CreateRequest(int(other.ID), decimal.NewFromInt(100), "", nil, approver.ID)
+	assert.NoError(suite.T(), err)
+
+	// A rolled back approval queues nothing
+	outsider := suite.CreateTestUser("Director")
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, outsider.ID, "")
+	assert.Error(suite.T(), err)
+
+	_, err = suite.requestUsecase.ApproveRequest(int(request.ID), 0, approver.ID, "")
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.outbox.DispatchPending(time.Now())
+	assert.NoError(suite.T(), err)
+
+	deliveries := suite.deliveriesOf(subscription.ID)
+	assert.Len(suite.T(), deliveries, 2)
+	assert.Equal(suite.T(), model.RequestEventCreated, deliveries[0].EventType)
+	assert.Equal(suite.T(), model.RequestEventApproved, deliveries[1].EventType)
+
+	_, err = suite.webhookUsecase.DispatchDue(time.Now())
+	assert.NoError(suite.T(), err)
+
+	assert.Len(suite.T(), receiver.calls, 2)
+	for i, call := range receiver.calls {
+		timestamp := call.Header.Get(usecase.WebhookTimestampHeader)
+		expected := "sha256=" + usecase.SignWebhookPayload(secret, timestamp, receiver.bodies[i])
+		assert.Equal(suite.T(), expected, call.Header.Get(usecase.WebhookSignatureHeader))
+	}
+	assert.Equal(suite.T(), model.RequestEventApproved, receiver.calls[1].Header.Get(usecase.WebhookEventHeader))
+
+	var payload map[string]interface{}
+	assert.NoError(suite.T(), json.Unmarshal(receiver.bodies[1], &payload))
+	assert.Equal(suite.T(), float64(request.ID), payload["request_id"])
+	assert.Equal(suite.T(), float64(workflow.ID), payload["workflow_id"])
+
+	for _, delivery := range suite.deliveriesOf(subscription.ID) {
+		assert.Equal(suite.T(), model.WebhookDeliveryDelivered, delivery.Status)
+		assert.Equal(suite.T(), uint(1), delivery.Attempts)
+		assert.Equal(suite.T(), http.StatusOK, delivery.ResponseStatus)
+	}
+}
+
+// Test failed deliveries are retried with backoff and can be redelivered
+func (suite *WebhookUsecaseTestSuite) TestDispatchDue_RetryAndRedeliver() {
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	approver := suite.CreateTestUser("Manager")
+	workflow := suite.CreateTestWorkflowWithSteps("Retried", "Manager")
+	subscription, _, err := suite.webhookUsecase.CreateSubscription(server.URL, "secret", &workflow.ID, []string{"created"}, approver.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.RequestEventCreated, subscription.Events)
+
+	_, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, approver.ID)
+	assert.NoError(suite.T(), err)
+
+	now := time.Now()
+	_, err = suite.outbox.DispatchPending(now)
+	assert.NoError(suite.T(), err)
+	_, err = suite.webhookUsecase.DispatchDue(now)
+	assert.NoError(suite.T(), err)
+
+	delivery := suite.deliveriesOf(subscription.ID)[0]
+	assert.Equal(suite.T(), model.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(suite.T(), uint(1), delivery.Attempts)
+	assert.Equal(suite.T(), http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.WithinDuration(suite.T(), now.Add(30*time.Second), *delivery.NextAttemptAt, time.Second)
+
+	// Not due yet
+	_, err = suite.webhookUsecase.DispatchDue(now.Add(10 * time.Second))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), receiver.calls, 1)
+
+	_, err = suite.webhookUsecase.DispatchDue(now.Add(31 * time.Second))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), receiver.calls, 2)
+
+	delivery = suite.deliveriesOf(subscription.ID)[0]
+	assert.Equal(suite.T(), model.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(suite.T(), uint(2), delivery.Attempts)
+
+	redelivered, err := suite.webhookUsecase.Redeliver(int(subscription.ID), int(delivery.ID))
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), delivery.ID, redelivered.ID)
+	assert.Equal(suite.T(), model.WebhookDeliveryDelivered, redelivered.Status)
+	assert.Equal(suite.T(), []byte(delivery.Payload), []byte(redelivered.Payload))
+	assert.Len(suite.T(), receiver.calls, 3)
+}
+
+// Test a delivery that cannot be attempted does not hold up the rest of the batch
+func (suite *WebhookUsecaseTestSuite) TestDispatchDue_FailureDoesNotStopBatch() {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	approver := suite.CreateTestUser("Manager")
+	workflow := suite.CreateTestWorkflowWithSteps("Batched", "Manager")
+	subscription, _, err := suite.webhookUsecase.CreateSubscription(server.URL, "", &workflow.ID, nil, approver.ID)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.requestUsecase.CreateRequest(int(workflow.ID), decimal.NewFromInt(100), "", nil, approver.ID)
+	assert.NoError(suite.T(), err)
+
+	now := time.Now()
+	_, err = suite.outbox.DispatchPending(now)
+	assert.NoError(suite.T(), err)
+
+	// Due before the good delivery, for a subscription that no longer exists
+	earlier := now.Add(-time.Minute)
+	orphan := model.WebhookDelivery{
+		SubscriptionID: subscription.ID + 1000,
+		RequestID:      1,
+		EventType:      model.RequestEventCreated,
+		Payload:        datatypes.JSON([]byte(`{}`)),
+		Status:         model.WebhookDeliveryPending,
+		NextAttemptAt:  &earlier,
+	}
+	suite.DB.Create(&orphan)
+	defer suite.DB.Delete(&model.WebhookDelivery{}, orphan.ID)
+
+	attempted, err := suite.webhookUsecase.DispatchDue(now)
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 2, attempted)
+	assert.Len(suite.T(), receiver.calls, 1)
+
+	delivery := suite.deliveriesOf(subscription.ID)[0]
+	assert.Equal(suite.T(), model.WebhookDeliveryDelivered, delivery.Status)
+
+	orphan = suite.deliveriesOf(orphan.SubscriptionID)[0]
+	assert.Equal(suite.T(), model.WebhookDeliveryPending, orphan.Status)
+	assert.Equal(suite.T(), uint(1), orphan.Attempts)
+	assert.NotEmpty(suite.T(), orphan.LastError)
+	assert.True(suite.T(), orphan.NextAttemptAt.After(now))
+}
+
+// Test subscription validation
+func (suite *WebhookUsecaseTestSuite) TestCreateSubscription_Invalid() {
+	_, _, err := suite.webhookUsecase.CreateSubscription("ftp://example.com/hook", "", nil, nil, 1)
+	assert.Equal(suite.T(), usecase.ErrInvalidWebhookURL, err)
+
+	_, _, err = suite.webhookUsecase.CreateSubscription("https://example.com/hook", "", nil, []string{"DELETED"}, 1)
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvalidWebhookEvent)
+	assert.True(suite.T(), strings.Contains(err.Error(), "DELETED"))
+}
+
+func TestWebhookUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookUsecaseTestSuite))
+}